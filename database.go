@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"time"
 
 	"github.com/jvitoroc/gobase/eval"
 	"github.com/jvitoroc/gobase/schema"
@@ -20,26 +21,83 @@ type database struct {
 	// reserved for internal tables/
 	// such as the users table
 	internalSchema *schema.Schema
+
+	// when compactInterval is set, the tables in which a vacuum would
+	// reclaim at least compactFraction of their pages are vacuumed in
+	// the background that often, until the database is closed
+	compactInterval time.Duration
+	compactFraction float64
+	compactor       *schema.Compactor
 }
 
 func (d *database) initialize(rootDir string) error {
-	file, err := os.OpenFile(path.Join(rootDir, "schema"), os.O_RDWR|os.O_CREATE, 0666)
+	// changes that were logged but may not have reached the
+	// table files are replayed before any of them is read
+	wal, err := schema.OpenWAL(rootDir)
 	if err != nil {
 		return err
 	}
-	sch := schema.NewSchema(rootDir)
 
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(sch)
-	if err != nil && !errors.Is(err, io.EOF) {
+	pool := schema.NewBufferPool(schema.DefaultBufferPoolPages)
+	pool.SetWAL(wal)
+	sch := schema.NewSchemaWithBufferPool(rootDir, pool)
+	if err := readSchema(rootDir, sch); err != nil {
 		return err
 	}
 
 	d.schema = sch
 
+	if d.compactInterval > 0 {
+		d.compactor = sch.StartCompactor(d.compactInterval, d.compactFraction)
+	}
+
 	return nil
 }
 
+// close stops the compactor, then writes the pages that are still only
+// in memory to the table files. The vacuums the compactor failed to do
+// the last time it checked the tables are reported as well.
+func (d *database) close() error {
+	var err error
+	if d.compactor != nil {
+		d.compactor.Stop()
+		err = d.compactor.Err()
+		d.compactor = nil
+	}
+
+	if pool := d.schema.BufferPool(); pool != nil {
+		err = errors.Join(err, pool.Close())
+	}
+
+	return err
+}
+
+// readSchema decodes the schema file of the directory into sch,
+// a directory without one has an empty schema.
+func readSchema(rootDir string, sch *schema.Schema) error {
+	file, err := os.Open(path.Join(rootDir, "schema"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = json.NewDecoder(file).Decode(sch)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+// initializeInMemory sets up a database whose tables are only kept in
+// memory, so nothing is read from or written to disk.
+func (d *database) initializeInMemory() {
+	d.schema = schema.NewSchemaWithEngine(schema.NewMemoryEngine())
+}
+
 func (d *database) run(ctx context.Context, r io.Writer, batch string) error {
 	p := sql.NewParser(batch)
 	sts, err := p.Parse()
@@ -51,6 +109,10 @@ func (d *database) run(ctx context.Context, r io.Writer, batch string) error {
 		return errors.New("empty batch")
 	}
 
+	if err := sql.Analyze(sts, d.schema); err != nil {
+		return err
+	}
+
 	for i, s := range sts {
 		if len(s.Clauses) == 0 {
 			return fmt.Errorf("empty Statement #%d", i+1)
@@ -61,6 +123,14 @@ func (d *database) run(ctx context.Context, r io.Writer, batch string) error {
 			if err := d.createTableStatement(ctx, r, s); err != nil {
 				return err
 			}
+		case sql.CreateType:
+			if err := d.createTypeStatement(ctx, r, s); err != nil {
+				return err
+			}
+		case sql.CreateIndex, sql.CreateUniqueIndex:
+			if err := d.createIndexStatement(ctx, r, s); err != nil {
+				return err
+			}
 		case sql.Select:
 			if err := d.selectStatement(ctx, r, s); err != nil {
 				return err
@@ -69,6 +139,14 @@ func (d *database) run(ctx context.Context, r io.Writer, batch string) error {
 			if err := d.InsertIntoStatement(ctx, r, s); err != nil {
 				return err
 			}
+		case sql.VerifyTable:
+			if err := d.verifyTableStatement(ctx, r, s); err != nil {
+				return err
+			}
+		case sql.Vacuum:
+			if err := d.vacuumStatement(ctx, r, s); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("invalid Statement #%d", i+1)
 		}
@@ -84,6 +162,7 @@ func (d *database) createTableStatement(ctx context.Context, r io.Writer, s *sql
 
 	tableName := ""
 	var columns []*schema.NewColumn
+	options := schema.TableOptions{}
 
 	for _, p := range s.Clauses {
 		switch p.Type {
@@ -91,10 +170,64 @@ func (d *database) createTableStatement(ctx context.Context, r io.Writer, s *sql
 			tableName = p.Body.(string)
 		case sql.Definitions:
 			columns = p.Body.([]*schema.NewColumn)
+		case sql.With:
+			options = p.Body.(schema.TableOptions)
+		}
+	}
+
+	_, err := d.schema.CreateTableWithOptions(tableName, columns, options)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *database) createTypeStatement(ctx context.Context, r io.Writer, s *sql.Statement) error {
+	if err := validateCreateTypeStatement(s); err != nil {
+		return err
+	}
+
+	typeName := ""
+	var labels []string
+
+	for _, p := range s.Clauses {
+		switch p.Type {
+		case sql.CreateType:
+			typeName = p.Body.(string)
+		case sql.AsEnum:
+			labels = p.Body.([]string)
 		}
 	}
 
-	_, err := d.schema.CreateTable(tableName, columns)
+	_, err := d.schema.CreateType(typeName, labels)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *database) createIndexStatement(ctx context.Context, r io.Writer, s *sql.Statement) error {
+	if err := validateCreateIndexStatement(s); err != nil {
+		return err
+	}
+
+	indexName := ""
+	unique := false
+	var on *sql.IndexOn
+
+	for _, p := range s.Clauses {
+		switch p.Type {
+		case sql.CreateIndex, sql.CreateUniqueIndex:
+			indexName = p.Body.(string)
+			unique = p.Type == sql.CreateUniqueIndex
+		case sql.On:
+			on = p.Body.(*sql.IndexOn)
+		}
+	}
+
+	_, err := d.schema.CreateIndex(indexName, on.Table, on.Columns, on.Using, unique)
 	if err != nil {
 		return err
 	}
@@ -108,14 +241,14 @@ func (d *database) InsertIntoStatement(ctx context.Context, r io.Writer, s *sql.
 	}
 
 	tableName := ""
-	var values []string
+	var exprs []*eval.Expression
 
 	for _, p := range s.Clauses {
 		switch p.Type {
 		case sql.InsertInto:
 			tableName = p.Body.(string)
 		case sql.Values:
-			values = p.Body.([]string)
+			exprs = p.Body.([]*eval.Expression)
 		}
 	}
 
@@ -124,6 +257,22 @@ func (d *database) InsertIntoStatement(ctx context.Context, r io.Writer, s *sql.
 		return fmt.Errorf("table with name '%s' does not exist", tableName)
 	}
 
+	// values are evaluated once per statement, so functions
+	// such as GEN_RANDOM_UUID() give a new value for each row
+	values := make([]string, len(exprs))
+	for i, expr := range exprs {
+		v, err := eval.Evaluate(expr, nil)
+		if err != nil {
+			return err
+		}
+
+		if v.GoValue == nil {
+			return fmt.Errorf("value #%d of the row results in nothing", i+1)
+		}
+
+		values[i] = schema.FormatValue(v.GoValue)
+	}
+
 	err := t.Insert(values)
 	if err != nil {
 		return err
@@ -138,13 +287,10 @@ func (d *database) selectStatement(ctx context.Context, r io.Writer, s *sql.Stat
 	}
 
 	tableName := ""
-	var returningColumns []string
 	var filter *eval.Expression
 
 	for _, p := range s.Clauses {
 		switch p.Type {
-		case sql.Select:
-			returningColumns, _ = p.Body.([]string)
 		case sql.From:
 			tableName, _ = p.Body.(string)
 		case sql.Where:
@@ -157,20 +303,51 @@ func (d *database) selectStatement(ctx context.Context, r io.Writer, s *sql.Stat
 		return fmt.Errorf("table with name '%s' does not exist", tableName)
 	}
 
-	err := t.Read(ctx, r, returningColumns, func(row *schema.DeserializedRow) (bool, error) {
-		r, err := eval.Evaluate(filter, row.Map())
+	filter = eval.Optimize(filter)
+	if filter != nil && filter.IsLiteral() {
+		if filter.GoValue == false {
+			return nil
+		}
+
+		if filter.GoValue == true {
+			filter = nil
+		}
+	}
+
+	program := eval.Program(func([]any) (any, error) {
+		return true, nil
+	})
+	if filter != nil {
+		var err error
+		program, err = eval.Compile(filter, t.ColumnNames())
+		if err != nil {
+			return err
+		}
+	}
+
+	shouldInclude := func(row *schema.DeserializedRow) (bool, error) {
+		v, err := program(row.Values())
 		if err != nil {
 			return false, err
 		}
 
-		if res, ok := r.GoValue.(bool); ok {
-			if ok {
-				return res, nil
-			}
+		if res, ok := v.(bool); ok {
+			return res, nil
 		}
 
 		return false, errors.New("WHERE clause is invalid, must result in a boolean result")
-	})
+	}
+
+	// columnar tables decode the columns the filter reads first,
+	// and the rest only for the rows it includes
+	columns := eval.Identifiers(filter)
+
+	var err error
+	if scan := planIndexScan(t, filter); scan != nil {
+		err = t.ReadIndex(ctx, r, columns, scan.index, scan.lower, scan.upper, shouldInclude)
+	} else {
+		err = t.Read(ctx, r, columns, shouldInclude)
+	}
 	if err != nil {
 		return err
 	}
@@ -257,6 +434,10 @@ func validateCreateTableStatement(s *sql.Statement) error {
 			}
 
 			hasDefinitions = true
+		case sql.With:
+			if _, ok := p.Body.(schema.TableOptions); !ok {
+				return errors.New("invalid table options")
+			}
 		}
 	}
 
@@ -271,6 +452,162 @@ func validateCreateTableStatement(s *sql.Statement) error {
 	return nil
 }
 
+func validateCreateTypeStatement(s *sql.Statement) error {
+	hasCreateType := false
+	hasAsEnum := false
+
+	for _, p := range s.Clauses {
+		switch p.Type {
+		case sql.CreateType:
+			b, ok := p.Body.(string)
+			if !ok {
+				return errors.New("invalid name for type")
+			}
+
+			if len(b) == 0 {
+				return errors.New("must provide name for type")
+			}
+
+			hasCreateType = true
+		case sql.AsEnum:
+			if _, ok := p.Body.([]string); !ok {
+				return errors.New("invalid labels")
+			}
+
+			hasAsEnum = true
+		}
+	}
+
+	if !hasCreateType {
+		return errors.New("missing CREATE TYPE clause")
+	}
+
+	if !hasAsEnum {
+		return errors.New("missing AS ENUM clause")
+	}
+
+	return nil
+}
+
+func validateCreateIndexStatement(s *sql.Statement) error {
+	hasCreateIndex := false
+	hasOn := false
+
+	for _, p := range s.Clauses {
+		switch p.Type {
+		case sql.CreateIndex, sql.CreateUniqueIndex:
+			b, ok := p.Body.(string)
+			if !ok {
+				return errors.New("invalid name for index")
+			}
+
+			if len(b) == 0 {
+				return errors.New("must provide name for index")
+			}
+
+			hasCreateIndex = true
+		case sql.On:
+			b, ok := p.Body.(*sql.IndexOn)
+			if !ok {
+				return errors.New("invalid table and columns for index")
+			}
+
+			if len(b.Columns) == 0 {
+				return errors.New("must provide columns for index")
+			}
+
+			hasOn = true
+		}
+	}
+
+	if !hasCreateIndex {
+		return errors.New("missing CREATE INDEX clause")
+	}
+
+	if !hasOn {
+		return errors.New("missing ON clause")
+	}
+
+	return nil
+}
+
+// verifyReport is written by VERIFY TABLE, it lists
+// every corruption found in the files of the table.
+type verifyReport struct {
+	Table       string
+	Corruptions []*schema.CorruptionError
+}
+
+func (d *database) verifyTableStatement(ctx context.Context, r io.Writer, s *sql.Statement) error {
+	tableName, ok := s.Clauses[0].Body.(string)
+	if !ok || len(tableName) == 0 {
+		return errors.New("must provide table name for verifying")
+	}
+
+	t := d.schema.GetTable(tableName)
+	if t == nil {
+		return fmt.Errorf("table with name '%s' does not exist", tableName)
+	}
+
+	found, err := t.Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := verifyReport{Table: tableName, Corruptions: []*schema.CorruptionError{}}
+	report.Corruptions = append(report.Corruptions, found...)
+
+	blob, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Write(blob)
+
+	return err
+}
+
+// vacuumStatement vacuums the table, or every table when none is given,
+// writing how many pages each of them had before and after.
+func (d *database) vacuumStatement(ctx context.Context, r io.Writer, s *sql.Statement) error {
+	tableName, ok := s.Clauses[0].Body.(string)
+	if !ok {
+		return errors.New("invalid table name")
+	}
+
+	var stats []*schema.VacuumStats
+	if tableName == "" {
+		var err error
+		if stats, err = d.schema.Vacuum(ctx); err != nil {
+			return err
+		}
+	} else {
+		t := d.schema.GetTable(tableName)
+		if t == nil {
+			return fmt.Errorf("table with name '%s' does not exist", tableName)
+		}
+
+		st, err := t.Vacuum(ctx)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, st)
+	}
+
+	for _, st := range stats {
+		blob, err := json.Marshal(st)
+		if err != nil {
+			return err
+		}
+
+		if _, err := r.Write(blob); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func validateInsertIntoStatement(s *sql.Statement) error {
 	hasInsertInto := false
 	hasValues := false
@@ -289,7 +626,7 @@ func validateInsertIntoStatement(s *sql.Statement) error {
 
 			hasInsertInto = true
 		case sql.Values:
-			b, ok := p.Body.([]string)
+			b, ok := p.Body.([]*eval.Expression)
 			if !ok {
 				return errors.New("invalid values")
 			}
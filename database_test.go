@@ -3,11 +3,16 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
 	"github.com/jvitoroc/gobase/schema"
 )
 
@@ -127,3 +132,1129 @@ func TestDatabaseCreateTable(t *testing.T) {
 		}
 	}
 }
+
+func TestDatabaseRejectsInvalidBatch(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE foo DEFINITIONS (
+			foo bool,
+			bar int
+		);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		INSERT INTO foo VALUES (true, 123);
+		SELECT foo FROM foo WHERE qux == 1;
+	`)
+	if err == nil || err.Error() != "statement #2: column 'qux' does not exist at 3:29" {
+		t.Errorf("expected invalid column error, but got '%v'", err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT foo FROM foo WHERE bar > 0;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no rows to be inserted, but got '%s'", buf.String())
+	}
+}
+
+func BenchmarkSelectStatement(b *testing.B) {
+	database := database{}
+	err := database.initialize(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	err = database.run(ctx, io.Discard, `CREATE TABLE foo DEFINITIONS (foo bool, bar int, baz string);`)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var batch strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&batch, `INSERT INTO foo VALUES (%t, %d, "row %d");`, i%2 == 0, i, i)
+	}
+
+	err = database.run(ctx, io.Discard, batch.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = database.run(ctx, io.Discard, `SELECT foo FROM foo WHERE foo == true AND bar > 1990 AND baz != "row 1";`)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDatabaseStaticWhere(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE foo DEFINITIONS (foo bool, bar int);
+		INSERT INTO foo VALUES (true, 1);
+		INSERT INTO foo VALUES (false, 2);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT foo FROM foo WHERE 1 > 2 AND bar == 1;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no rows, but got '%s'", buf.String())
+		return
+	}
+
+	err = database.run(ctx, buf, `SELECT foo FROM foo WHERE 2 > 1 OR bar == 1;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 2 {
+		t.Errorf("expected 2 rows, but got %d", n)
+	}
+}
+
+func TestDatabaseBigint(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE events DEFINITIONS (id bigint, at bigint, n int);
+		INSERT INTO events VALUES (9007199254740993, 1700000000000, 1);
+		INSERT INTO events VALUES (9007199254740992, 1700000000001, 2);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE id == 9007199254740993 AND at >= 1700000000000 AND n < 1.5;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 {
+		t.Errorf("expected 1 row, but got %d", n)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Value":9007199254740993`) {
+		t.Errorf("expected id to be returned exactly, but got '%s'", buf.String())
+		return
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO events VALUES (-5, -1700000000000, -3);`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE id > -10 AND id < 0 AND n == -3;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Value":-5}`) || !strings.Contains(buf.String(), `"Value":-1700000000000}`) {
+		t.Errorf("expected the negative row, but got '%s'", buf.String())
+		return
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE -n == 3 AND -(id + 1) == 4;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 || !strings.Contains(buf.String(), `"Value":-5}`) {
+		t.Errorf("expected the negative row, but got '%s'", buf.String())
+	}
+}
+
+func TestDatabaseFloat(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE readings DEFINITIONS (sensor int, value double);
+		INSERT INTO readings VALUES (1, 20.5);
+		INSERT INTO readings VALUES (2, 21);
+		INSERT INTO readings VALUES (3, 19.75);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT sensor FROM readings WHERE value > 20 AND value <= 21;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 2 {
+		t.Errorf("expected 2 rows, but got %d", n)
+		return
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT sensor FROM readings WHERE value == 21 OR value == 19.75;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 2 {
+		t.Errorf("expected 2 rows, but got %d", n)
+	}
+}
+
+func TestDatabaseTemporal(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE events DEFINITIONS (id int, day date, at time, created timestamp);
+		INSERT INTO events VALUES (1, "2024-01-31", "09:30", "2024-01-31T09:30:00Z");
+		INSERT INTO events VALUES (2, DATE "2024-02-01", TIME "18:00:00", TIMESTAMP "2024-02-01 18:00:00.5");
+		INSERT INTO events VALUES (3, "2024-03-15", "23:59:59.999999", "2024-03-15T23:59:59.999999-03:00");
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		where string
+		rows  int
+	}{
+		{where: `day >= DATE "2024-02-01"`, rows: 2},
+		{where: `day + 1 == DATE "2024-02-01"`, rows: 1},
+		{where: `created > TIMESTAMP "2024-03-16T00:00:00Z"`, rows: 1},
+		{where: `created - INTERVAL "1 month" < DATE "2024-01-01"`, rows: 1},
+		{where: `at > TIME "12:00"`, rows: 2},
+		{where: `EXTRACT(month FROM day) == 2 OR EXTRACT(hour FROM at) == 9`, rows: 2},
+		{where: `DATE_TRUNC("month", created) == TIMESTAMP "2024-01-01T00:00:00Z"`, rows: 1},
+		{where: `created < NOW()`, rows: 3},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		err = database.run(ctx, buf, `SELECT id FROM events WHERE `+tt.where+`;`)
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+
+		if n := strings.Count(buf.String(), `{"Columns"`); n != tt.rows {
+			t.Errorf("%s: expected %d rows, but got %d", tt.where, tt.rows, n)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE id == 2;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, want := range []string{`"2024-02-01"`, `"18:00:00"`, `"2024-02-01T18:00:00.5Z"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in %s", want, buf.String())
+		}
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `SELECT id FROM events WHERE day > "2024-01-01";`)
+	if err == nil || !strings.Contains(err.Error(), "'date' and 'string'") {
+		t.Errorf("expected comparison between date and string to fail, but got %v", err)
+	}
+
+	// date columns keep only the day of timestamps
+	err = database.run(ctx, &bytes.Buffer{}, `
+		INSERT INTO events VALUES (4, NOW(), "12:00", NOW());
+		INSERT INTO events VALUES (5, TIMESTAMP "2024-04-01 10:00", "12:00", NOW());
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE id == 4 AND day <= NOW() AND day + 1 > NOW() OR day == DATE "2024-04-01";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 2 {
+		t.Errorf("expected the days of both timestamps to be kept, but got %s", buf.String())
+	}
+}
+
+func TestDatabaseDecimal(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE payments DEFINITIONS (id int, amount decimal(8, 2));
+		INSERT INTO payments VALUES (1, 0.1);
+		INSERT INTO payments VALUES (2, 0.2);
+		INSERT INTO payments VALUES (3, 100.01);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO payments VALUES (4, 100.005);`)
+	if err == nil || !strings.Contains(err.Error(), "value '100.005' has more fractional digits than decimal(8,2) holds") {
+		t.Errorf("expected a value with too many fractional digits to be rejected, but got %v", err)
+	}
+
+	tests := []struct {
+		where string
+		rows  int
+	}{
+		{where: `amount + 0.2 == 0.3`, rows: 1},
+		{where: `amount * 3 == 0.6`, rows: 1},
+		{where: `amount == 100.01`, rows: 1},
+		{where: `amount / 3 == 0.033333`, rows: 1},
+		{where: `ROUND(amount / 3, 2) == 0.03`, rows: 1},
+		{where: `amount > 0.15 AND amount < 100`, rows: 1},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		err = database.run(ctx, buf, `SELECT id FROM payments WHERE `+tt.where+`;`)
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+
+		if n := strings.Count(buf.String(), `{"Columns"`); n != tt.rows {
+			t.Errorf("%s: expected %d rows, but got %d", tt.where, tt.rows, n)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id FROM payments WHERE id == 1;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Precision":8,"Scale":2,"Value":0.10}`) {
+		t.Errorf("expected decimal to be written with its scale, but got %s", buf.String())
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO payments VALUES (4, 1000000);`)
+	if err == nil || !strings.Contains(err.Error(), "data type is decimal(8,2), value '1000000' doesn't fit in decimal(8,2)") {
+		t.Errorf("expected value with too many digits to be rejected, but got %v", err)
+	}
+}
+
+func TestDatabaseBytes(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE files DEFINITIONS (id int, hash bytes);
+		INSERT INTO files VALUES (1, x"deadbeef");
+		INSERT INTO files VALUES (2, "00ff");
+		INSERT INTO files VALUES (3, X"");
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		where string
+		rows  int
+	}{
+		{where: `hash == x"DEADBEEF"`, rows: 1},
+		{where: `hash != x"deadbeef"`, rows: 2},
+		{where: `hash > x"00"`, rows: 2},
+		{where: `hash < x"00ff"`, rows: 1},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		err = database.run(ctx, buf, `SELECT id FROM files WHERE `+tt.where+`;`)
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+
+		if n := strings.Count(buf.String(), `{"Columns"`); n != tt.rows {
+			t.Errorf("%s: expected %d rows, but got %d", tt.where, tt.rows, n)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id FROM files WHERE id == 1;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Value":"3q2+7w=="`) {
+		t.Errorf("expected bytes to be written as base64, but got %s", buf.String())
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `SELECT id FROM files WHERE hash == "deadbeef";`)
+	if err == nil || !strings.Contains(err.Error(), "'bytes' and 'string'") {
+		t.Errorf("expected comparison between bytes and string to fail, but got %v", err)
+	}
+}
+
+func TestDatabaseUUID(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE users DEFINITIONS (id uuid, name string);
+		INSERT INTO users VALUES (GEN_RANDOM_UUID(), "a");
+		INSERT INTO users VALUES (GEN_RANDOM_UUID(), "b");
+		INSERT INTO users VALUES (UUIDV7(), "c");
+		INSERT INTO users VALUES ("0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10", "d");
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id FROM users WHERE name != "d";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	seen := map[string]bool{}
+	for dec := json.NewDecoder(buf); dec.More(); {
+		var row struct {
+			Columns []struct {
+				Value string
+			}
+		}
+		if err := dec.Decode(&row); err != nil {
+			t.Error(err)
+			return
+		}
+
+		id, err := uuid.Parse(row.Columns[0].Value)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if seen[id.String()] {
+			t.Errorf("expected generated uuids to be unique, but got %s twice", id)
+		}
+		seen[id.String()] = true
+	}
+
+	if len(seen) != 3 {
+		t.Errorf("expected 3 generated uuids, but got %d", len(seen))
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT name FROM users WHERE id == UUID "0190A5E0-7C4B-7CC2-9A35-4C2F7A0B8F10";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 || !strings.Contains(buf.String(), `"Value":"d"`) {
+		t.Errorf("expected to find the row by its uuid, but got %s", buf.String())
+	}
+}
+
+func TestDatabaseJSON(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE events DEFINITIONS (id int, doc json);
+		INSERT INTO events VALUES (1, '{"type": "click", "tags": ["a", "b"]}');
+		INSERT INTO events VALUES (2, '{"type": "view", "tags": []}');
+		INSERT INTO events VALUES (3, '{"tags": ["it''s"]}');
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id, doc FROM events WHERE doc->>'type' == 'click' and json_array_length(doc->'tags') == 2;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if want := `"Value":{"type":"click","tags":["a","b"]}`; strings.Count(buf.String(), `{"Columns"`) != 1 || !strings.Contains(buf.String(), want) {
+		t.Errorf("expected a single row with the compacted document %s, but got %s", want, buf.String())
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE doc->'tags'->>0 == "it's";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 || !strings.Contains(buf.String(), `"Value":3`) {
+		t.Errorf("expected to find the row by its first tag, but got %s", buf.String())
+	}
+
+	// the third event has no type to be ordered
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE doc->>'type' > "d";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 || !strings.Contains(buf.String(), `"Value":2`) {
+		t.Errorf("expected to find the row whose type comes after 'd', but got %s", buf.String())
+	}
+
+	// paths past a missing key or a scalar give nothing all the way down
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT id FROM events WHERE doc->'meta'->'tags'->>0 == "a" or doc->'type'->'x'->>0 == "a" or doc->'tags'->>0 == "a";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 || !strings.Contains(buf.String(), `"Value":1`) {
+		t.Errorf("expected to find the row by its first tag, but got %s", buf.String())
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO events VALUES (4, '{"type": }');`)
+	if err == nil {
+		t.Error("expected an invalid document to be rejected")
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `SELECT id FROM events WHERE doc == doc;`)
+	if err == nil || !strings.Contains(err.Error(), "json documents can't be compared") {
+		t.Errorf("expected comparing documents to fail, but got %v", err)
+	}
+}
+
+func TestDatabaseLengthBoundedStrings(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE countries DEFINITIONS (code char(3), name varchar(12));
+		INSERT INTO countries VALUES ("br", "Brazil");
+		INSERT INTO countries VALUES ("PRT", "Portugal");
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO countries VALUES ("USA", "United States");`)
+	if err == nil || !strings.Contains(err.Error(), "is too long for this column") {
+		t.Errorf("expected a value longer than the column to be rejected, but got %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT code, name FROM countries WHERE code == "br";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, want := range []string{`"Type":"char","Length":3,"Value":"br"`, `"Type":"varchar","Length":12,"Value":"Brazil"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in %s", want, buf.String())
+		}
+	}
+}
+
+func TestDatabaseEnum(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TYPE status AS ENUM ("pending", "shipped", "delivered");
+		CREATE TABLE orders DEFINITIONS (id int, state status);
+		INSERT INTO orders VALUES (1, "delivered");
+		INSERT INTO orders VALUES (2, "pending");
+		INSERT INTO orders VALUES (3, "shipped");
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO orders VALUES (4, "lost");`)
+	if err == nil || !strings.Contains(err.Error(), "value 'lost' is invalid for this column") {
+		t.Errorf("expected a value that isn't a label to be rejected, but got %v", err)
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `CREATE TABLE others DEFINITIONS (state unknown);`)
+	if err == nil || !strings.Contains(err.Error(), "type with name 'unknown' does not exist") {
+		t.Errorf("expected an unknown type to be rejected, but got %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT id FROM orders WHERE state >= "shipped";`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 2 || strings.Contains(buf.String(), `"Value":2`) {
+		t.Errorf("expected the shipped and delivered orders, but got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = database.run(ctx, buf, `SELECT state FROM orders WHERE id == 1;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Value":"delivered"`) {
+		t.Errorf("expected the label of the value, but got %s", buf.String())
+	}
+}
+
+func TestDatabaseArrays(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE posts DEFINITIONS (id int, tags string[], scores int[]);
+		INSERT INTO posts VALUES (1, ["go", "db"], [3, 1]);
+		INSERT INTO posts VALUES (2, ["rust"], []);
+		INSERT INTO posts VALUES (3, [], [5]);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		{where: `tags[1] == "go"`, want: []string{`"Value":1`}},
+		{where: `"rust" == ANY(tags)`, want: []string{`"Value":2`}},
+		{where: `ANY(scores) > 2`, want: []string{`"Value":1`, `"Value":3`}},
+		{where: `tags @> ["db"]`, want: []string{`"Value":1`}},
+		{where: `tags <@ ["go", "db", "rust"] and cardinality(tags) > 0`, want: []string{`"Value":1`, `"Value":2`}},
+		{where: `scores && [1, 5]`, want: []string{`"Value":1`, `"Value":3`}},
+		{where: `tags == []`, want: []string{`"Value":3`}},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		err = database.run(ctx, buf, `SELECT id FROM posts WHERE `+tt.where+`;`)
+		if err != nil {
+			t.Errorf("%s: %s", tt.where, err)
+			continue
+		}
+
+		if n := strings.Count(buf.String(), `{"Columns"`); n != len(tt.want) {
+			t.Errorf("%s: expected %d rows, but got %s", tt.where, len(tt.want), buf.String())
+			continue
+		}
+
+		for _, w := range tt.want {
+			if !strings.Contains(buf.String(), w) {
+				t.Errorf("%s: expected %s in %s", tt.where, w, buf.String())
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = database.run(ctx, buf, `SELECT tags, scores FROM posts WHERE id == 1;`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, want := range []string{`"Type":"string[]","Value":["go","db"]`, `"Type":"int[]","Value":[3,1]`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %s in %s", want, buf.String())
+		}
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO posts VALUES (4, ["a"], ["b"]);`)
+	if err == nil || !strings.Contains(err.Error(), "is invalid for this column") {
+		t.Errorf("expected elements of the wrong type to be rejected, but got %v", err)
+	}
+}
+
+func TestDatabaseIndex(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	batch := &strings.Builder{}
+	batch.WriteString(`CREATE TABLE users DEFINITIONS (id int, email string, age int);`)
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(batch, `INSERT INTO users VALUES (%d, "user%d@example.com", %d);`, i, i, 20+i%50)
+	}
+	batch.WriteString(`
+		CREATE UNIQUE INDEX users_email ON users (email);
+		CREATE INDEX users_age ON users (age);
+		CREATE INDEX users_id ON users USING HASH (id);
+		INSERT INTO users VALUES (200, "user200@example.com", 99);
+	`)
+
+	if err := database.run(ctx, &bytes.Buffer{}, batch.String()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `INSERT INTO users VALUES (201, "user7@example.com", 30);`)
+	if err == nil || !strings.Contains(err.Error(), "duplicate value for unique index 'users_email'") {
+		t.Errorf("expected a duplicated email to be rejected, but got %v", err)
+	}
+
+	tests := []struct {
+		where    string
+		expected int
+	}{
+		{where: `email == "user42@example.com"`, expected: 1},
+		{where: `email == "user201@example.com"`, expected: 0},
+		{where: `age == 99`, expected: 1},
+		{where: `age >= 65 AND age < 70 AND id > 100`, expected: 10},
+		{where: `age < 21 OR id == 3`, expected: 5},
+		{where: `id == 200`, expected: 1},
+		{where: `id == 7.0 AND age == 27`, expected: 1},
+		{where: `id == 7.5`, expected: 0},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		if err := database.run(ctx, buf, `SELECT id FROM users WHERE `+test.where+`;`); err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if n := strings.Count(buf.String(), `{"Columns"`); n != test.expected {
+			t.Errorf("expected %d rows where %s, but got %d", test.expected, test.where, n)
+		}
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `CREATE INDEX users_age ON users (id);`)
+	if err == nil || !strings.Contains(err.Error(), "index with name 'users_age' already exists") {
+		t.Errorf("expected the index name to be taken, but got %v", err)
+	}
+}
+
+func TestDatabaseVerifyTable(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err = database.run(ctx, &bytes.Buffer{}, `
+		CREATE TABLE foo DEFINITIONS (id int, name string);
+		INSERT INTO foo VALUES (1, "a");
+		CREATE INDEX foo_id ON foo (id);
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := database.run(ctx, buf, `VERIFY TABLE foo;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if diff := cmp.Diff(`{"Table":"foo","Corruptions":[]}`, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `VERIFY TABLE bar;`)
+	if err == nil || err.Error() != "statement #1: table with name 'bar' does not exist" {
+		t.Errorf("expected the table not to exist, but got %v", err)
+	}
+}
+
+func TestDatabaseCompression(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	name := strings.Repeat("name", 50)
+
+	batch := &strings.Builder{}
+	batch.WriteString(`
+		CREATE TABLE foo DEFINITIONS (id int, name string) WITH (compression = "flate");
+		CREATE TABLE bar DEFINITIONS (id int, name string);
+		CREATE INDEX foo_id ON foo (id);
+	`)
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(batch, `INSERT INTO foo VALUES (%d, "%s"); INSERT INTO bar VALUES (%d, "%s");`, i, name, i, name)
+	}
+
+	if err := database.run(ctx, &bytes.Buffer{}, batch.String()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	stats, err := database.schema.Vacuum(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if stats[0].PagesAfter >= stats[1].PagesAfter {
+		t.Errorf("expected the compressed table to take fewer pages than %d, but got %d", stats[1].PagesAfter, stats[0].PagesAfter)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := database.run(ctx, buf, `SELECT id, name FROM foo WHERE id == 123;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Value":123}`) || !strings.Contains(buf.String(), name) {
+		t.Errorf("expected the row to be read back as it was inserted, but got %s", buf.String())
+	}
+}
+
+func TestDatabaseColumnar(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	batch := &strings.Builder{}
+	batch.WriteString(`CREATE TABLE events DEFINITIONS (id int, kind string, amount float) WITH (layout = "columnar");`)
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(batch, `INSERT INTO events VALUES (%d, "kind%d", %d.5);`, i, i%4, i)
+	}
+
+	if err := database.run(ctx, &bytes.Buffer{}, batch.String()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := database.run(ctx, buf, `SELECT id FROM events WHERE kind == "kind2" AND amount > 290;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 3 || !strings.Contains(buf.String(), `"Value":298.5}`) {
+		t.Errorf("expected rows 290, 294 and 298, but got %s", buf.String())
+	}
+
+	err = database.run(ctx, &bytes.Buffer{}, `CREATE INDEX events_id ON events (id);`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf.Reset()
+	if err := database.run(ctx, buf, `SELECT id FROM events WHERE id == 123;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.Contains(buf.String(), `"Value":"kind3"}`) {
+		t.Errorf("expected row 123 to be found by the index, but got %s", buf.String())
+	}
+}
+
+func TestDatabaseVacuum(t *testing.T) {
+	database := database{}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	batch := &strings.Builder{}
+	batch.WriteString(`
+		CREATE TABLE foo DEFINITIONS (id int, name string);
+		CREATE TABLE bar DEFINITIONS (id int);
+		CREATE INDEX foo_id ON foo (id);
+	`)
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(batch, `INSERT INTO foo VALUES (%d, "name%d");`, i, i)
+	}
+
+	if err := database.run(ctx, &bytes.Buffer{}, batch.String()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := database.run(ctx, buf, `VACUUM foo; VACUUM;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// rows are never deleted, so there's no space to reclaim
+	expected := `{"Table":"foo","PagesBefore":2,"PagesAfter":2}` +
+		`{"Table":"foo","PagesBefore":2,"PagesAfter":2}{"Table":"bar","PagesBefore":0,"PagesAfter":0}`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	buf.Reset()
+	if err := database.run(ctx, buf, `SELECT id FROM foo WHERE id == 123;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 {
+		t.Errorf("expected the index to find 1 row once vacuumed, but got %d", n)
+	}
+}
+
+func TestDatabaseCompactor(t *testing.T) {
+	database := database{compactInterval: time.Millisecond, compactFraction: 0.5}
+	err := database.initialize(t.TempDir())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if database.compactor == nil {
+		t.Error("expected the compactor to be started")
+		return
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	batch := &strings.Builder{}
+	batch.WriteString(`CREATE TABLE foo DEFINITIONS (id int, name string); CREATE INDEX foo_id ON foo (id);`)
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(batch, `INSERT INTO foo VALUES (%d, "name%d");`, i, i)
+	}
+
+	if err := database.run(ctx, &bytes.Buffer{}, batch.String()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := database.close(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if database.compactor != nil {
+		t.Error("expected the compactor to be stopped")
+	}
+}
+
+func TestDatabaseInMemory(t *testing.T) {
+	database := database{}
+	database.initializeInMemory()
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	batch := &strings.Builder{}
+	batch.WriteString(`
+		CREATE TABLE foo DEFINITIONS (id int, name string);
+		CREATE UNIQUE INDEX foo_id ON foo (id);
+	`)
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(batch, `INSERT INTO foo VALUES (%d, "name%d");`, i, i)
+	}
+
+	if err := database.run(ctx, &bytes.Buffer{}, batch.String()); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err := database.run(ctx, &bytes.Buffer{}, `INSERT INTO foo VALUES (7, "again");`)
+	if err == nil || !strings.Contains(err.Error(), "duplicate value for unique index 'foo_id'") {
+		t.Errorf("expected a duplicate value error, but got %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := database.run(ctx, buf, `SELECT id FROM foo WHERE id >= 123 AND id < 126;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 3 || !strings.Contains(buf.String(), `"Value":"name124"}`) {
+		t.Errorf("expected rows 123 to 125, but got %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := database.run(ctx, buf, `VERIFY TABLE foo; VACUUM foo;`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if diff := cmp.Diff(`{"Table":"foo","Corruptions":[]}{"Table":"foo","PagesBefore":0,"PagesAfter":0}`, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,172 @@
+package eval
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Array is the value of array columns and literals, all
+// of its elements are of the same type.
+type Array []any
+
+// ArrayOf gives the type of arrays of the given type, the
+// element type of empty array literals is unknown, which
+// is written as an empty type.
+func ArrayOf(t ValueType) ValueType {
+	return t + "[]"
+}
+
+// ElementType gives the type of the elements of an array type,
+// it reports false when the type isn't an array.
+func ElementType(t ValueType) (ValueType, bool) {
+	e, ok := strings.CutSuffix(string(t), "[]")
+	return ValueType(e), ok
+}
+
+// anyOf is the type of ANY(array), which can only be compared.
+func anyOf(t ValueType) ValueType {
+	return "any " + t
+}
+
+func quantifiedType(t ValueType) (ValueType, bool) {
+	e, ok := strings.CutPrefix(string(t), "any ")
+	return ValueType(e), ok
+}
+
+// anyElement holds the elements of an array given to ANY, a comparison
+// against it is true when it's true for at least one of the elements.
+type anyElement Array
+
+func arrayType(v Array) ValueType {
+	if len(v) == 0 {
+		return ArrayOf("")
+	}
+
+	return ArrayOf(genericValueType(v[0]))
+}
+
+// quantified lets a comparison be applied to ANY of the elements of an array,
+// which may be on either side, since comparisons can be mirrored.
+func quantified(op binaryOperator) binaryOperator {
+	return func(left, right any) (any, error) {
+		l, lany := left.(anyElement)
+		r, rany := right.(anyElement)
+
+		switch {
+		case lany && rany:
+			return nil, errors.New("ANY can only be applied to one side of a comparison")
+		case rany:
+			return anyMatches(r, func(e any) (any, error) {
+				return op(left, e)
+			})
+		case lany:
+			return anyMatches(l, func(e any) (any, error) {
+				return op(e, right)
+			})
+		}
+
+		return op(left, right)
+	}
+}
+
+// anyMatches tells if test is true for any of the elements.
+func anyMatches(elements anyElement, test func(e any) (any, error)) (any, error) {
+	for _, e := range elements {
+		v, err := test(e)
+		if err != nil {
+			return nil, err
+		}
+
+		if v == true {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// arrayIndex gives the element at the 1-based index, or nothing
+// when the array has no such element.
+func arrayIndex(array, index any) (any, error) {
+	a, ok := array.(Array)
+	if !ok {
+		return nil, fmt.Errorf("can't index '%s'", genericValueType(array))
+	}
+
+	i, ok := index.(int64)
+	if !ok {
+		return nil, fmt.Errorf("array index must be an integer, but got '%v'", index)
+	}
+
+	if i < 1 || i > int64(len(a)) {
+		return nil, nil
+	}
+
+	return a[i-1], nil
+}
+
+// contains tells if every element of sub is an element of a.
+func contains(a, sub any) (any, error) {
+	l, lok := a.(Array)
+	r, rok := sub.(Array)
+	if !lok || !rok {
+		return nil, fmt.Errorf("containment can only be checked between arrays, but got '%s' and '%s'", genericValueType(a), genericValueType(sub))
+	}
+
+	for _, e := range r {
+		if !hasElement(l, e) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// overlaps tells if the arrays have any element in common.
+func overlaps(a, b any) (any, error) {
+	l, lok := a.(Array)
+	r, rok := b.(Array)
+	if !lok || !rok {
+		return nil, fmt.Errorf("overlap can only be checked between arrays, but got '%s' and '%s'", genericValueType(a), genericValueType(b))
+	}
+
+	for _, e := range r {
+		if hasElement(l, e) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func hasElement(a Array, v any) bool {
+	for _, e := range a {
+		if equal(e, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareArrays compares the elements of both arrays in order, a
+// shorter array is ordered first when all of its elements are equal
+// to the ones of the other. It reports false when they aren't arrays.
+func compareArrays(left, right any) (int, bool, error) {
+	l, lok := left.(Array)
+	r, rok := right.(Array)
+	if !lok || !rok {
+		return 0, false, nil
+	}
+
+	for i := 0; i < len(l) && i < len(r); i++ {
+		c, err := Compare(l[i], r[i])
+		if err != nil || c != 0 {
+			return c, true, err
+		}
+	}
+
+	return cmp.Compare(len(l), len(r)), true, nil
+}
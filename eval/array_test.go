@@ -0,0 +1,58 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArrayOperators(t *testing.T) {
+	tags := Array{"go", "db", "go"}
+
+	tests := []struct {
+		operator    OperatorType
+		left, right any
+		want        any
+		wantErr     string
+	}{
+		{operator: Index, left: tags, right: int64(1), want: "go"},
+		{operator: Index, left: tags, right: int64(3), want: "go"},
+		{operator: Index, left: tags, right: int64(0), want: nil},
+		{operator: Index, left: tags, right: int64(4), want: nil},
+		{operator: Index, left: tags, right: 1.5, wantErr: "array index must be an integer, but got '1.5'"},
+		{operator: Contains, left: tags, right: Array{"db", "go"}, want: true},
+		{operator: Contains, left: tags, right: Array{}, want: true},
+		{operator: Contains, left: tags, right: Array{"db", "rust"}, want: false},
+		{operator: ContainedBy, left: Array{"db"}, right: tags, want: true},
+		{operator: ContainedBy, left: tags, right: Array{"db"}, want: false},
+		{operator: Overlaps, left: tags, right: Array{"rust", "db"}, want: true},
+		{operator: Overlaps, left: tags, right: Array{}, want: false},
+		{operator: Equal, left: "db", right: anyElement(tags), want: true},
+		{operator: Equal, left: anyElement(tags), right: "rust", want: false},
+		{operator: NotEqual, left: "go", right: anyElement(tags), want: true},
+		// ANY(scores) > 4 once mirrored
+		{operator: LessThan, left: int64(4), right: anyElement{int64(3), int64(5)}, want: true},
+		{operator: GreaterThan, left: anyElement{int64(3), int64(5)}, right: int64(4), want: true},
+		{operator: GreaterThan, left: anyElement{int64(3), int64(5)}, right: int64(5), want: false},
+		{operator: Equal, left: Array{int64(1), int64(2)}, right: Array{int64(1), int64(2)}, want: true},
+		{operator: Equal, left: Array{int64(1)}, right: Array{"1"}, want: false},
+		{operator: LessThan, left: Array{int64(1)}, right: Array{int64(1), int64(0)}, want: true},
+		{operator: Equal, left: anyElement(tags), right: anyElement(tags), wantErr: "ANY can only be applied to one side of a comparison"},
+	}
+
+	for i, tt := range tests {
+		got, err := binaryOperators[tt.operator](tt.left, tt.right)
+		gotErr := ""
+		if err != nil {
+			gotErr = err.Error()
+		}
+
+		if gotErr != tt.wantErr {
+			t.Errorf("#%d expected error '%s', but got '%s'", i, tt.wantErr, gotErr)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d expected %v, but got %v", i, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Program is an expression compiled against a fixed list of columns,
+// it receives the values of a row in the same order as those columns.
+type Program func(row []any) (any, error)
+
+// Compile turns the expression tree into a Program. Identifiers are resolved
+// to their position in columns and operators are picked once, so running the
+// program doesn't walk the tree, look values up by name or allocate results.
+func Compile(expr *Expression, columns []string) (Program, error) {
+	switch expr.Type {
+	case Operand:
+		if expr.Identifier != "" {
+			return compileIdentifier(expr.Identifier, columns)
+		}
+
+		v := expr.GoValue
+		return func([]any) (any, error) {
+			return v, nil
+		}, nil
+	case Operator:
+		return compileOperator(expr, columns)
+	case Case:
+		return compileCase(expr, columns)
+	case Function:
+		return compileFunction(expr, columns)
+	}
+
+	return nil, errors.New("unknown expression type")
+}
+
+func compileIdentifier(name string, columns []string) (Program, error) {
+	i := slices.Index(columns, name)
+	if i == -1 {
+		return nil, fmt.Errorf("value '%s' does not exist", name)
+	}
+
+	return func(row []any) (any, error) {
+		v := row[i]
+		if v == nil {
+			return nil, fmt.Errorf("value '%s' does not exist", name)
+		}
+
+		return v, nil
+	}, nil
+}
+
+func compileOperator(expr *Expression, columns []string) (Program, error) {
+	left, err := Compile(expr.Left, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := Compile(expr.Right, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.Operator {
+	case And:
+		return func(row []any) (any, error) {
+			l, err := compiledLogicalOperand(left, row)
+			if err != nil || !l {
+				return false, err
+			}
+
+			return compiledLogicalOperand(right, row)
+		}, nil
+	case Or:
+		return func(row []any) (any, error) {
+			l, err := compiledLogicalOperand(left, row)
+			if err != nil || l {
+				return l, err
+			}
+
+			return compiledLogicalOperand(right, row)
+		}, nil
+	}
+
+	op, ok := binaryOperators[expr.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator '%s'", expr.Operator)
+	}
+
+	return func(row []any) (any, error) {
+		l, err := left(row)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := right(row)
+		if err != nil {
+			return nil, err
+		}
+
+		return op(l, r)
+	}, nil
+}
+
+func compiledLogicalOperand(p Program, row []any) (bool, error) {
+	v, err := p(row)
+	if err != nil {
+		return false, err
+	}
+
+	return logicalOperand(v)
+}
+
+func compileCase(expr *Expression, columns []string) (Program, error) {
+	type branch struct {
+		when Program
+		then Program
+	}
+
+	var subject Program
+	if expr.Subject != nil {
+		var err error
+		subject, err = Compile(expr.Subject, columns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	branches := make([]branch, len(expr.Branches))
+	for i, b := range expr.Branches {
+		when, err := Compile(b.When, columns)
+		if err != nil {
+			return nil, err
+		}
+
+		then, err := Compile(b.Then, columns)
+		if err != nil {
+			return nil, err
+		}
+
+		branches[i] = branch{when: when, then: then}
+	}
+
+	els := Program(func([]any) (any, error) {
+		return nil, nil
+	})
+	if expr.Else != nil {
+		var err error
+		els, err = Compile(expr.Else, columns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(row []any) (any, error) {
+		var s any
+		if subject != nil {
+			var err error
+			s, err = subject(row)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, b := range branches {
+			when, err := b.when(row)
+			if err != nil {
+				return nil, err
+			}
+
+			matched, err := caseMatches(subject != nil, s, when)
+			if err != nil {
+				return nil, err
+			}
+
+			if matched {
+				return b.then(row)
+			}
+		}
+
+		return els(row)
+	}, nil
+}
+
+func compileFunction(expr *Expression, columns []string) (Program, error) {
+	f, ok := functions[expr.Function]
+	if !ok {
+		return nil, fmt.Errorf("function '%s' does not exist", expr.Function)
+	}
+
+	args := make([]Program, len(expr.Args))
+	for i, a := range expr.Args {
+		p, err := Compile(a, columns)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = p
+	}
+
+	return func(row []any) (any, error) {
+		values := make([]any, len(args))
+		for i, a := range args {
+			v, err := a(row)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+
+		return f.call(values)
+	}, nil
+}
@@ -0,0 +1,204 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+var compileTestColumns = []string{"foo", "bar", "baz"}
+
+func compileTestExpression() *Expression {
+	return &Expression{
+		Type:     Operator,
+		Operator: And,
+		Left: &Expression{
+			Type:     Operator,
+			Operator: And,
+			Left: &Expression{
+				Type:     Operator,
+				Operator: GreaterThan,
+				Left:     &Expression{Type: Operand, GoValue: float64(3)},
+				Right:    &Expression{Type: Operand, GoValue: float64(2)},
+			},
+			Right: &Expression{
+				Type:     Operator,
+				Operator: GreaterEqualThan,
+				Left:     &Expression{Type: Operand, Identifier: "foo"},
+				Right:    &Expression{Type: Operand, GoValue: float64(23)},
+			},
+		},
+		Right: &Expression{
+			Type:     Operator,
+			Operator: Equal,
+			Left: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{
+						When: &Expression{Type: Operand, Identifier: "baz"},
+						Then: &Expression{Type: Operand, Identifier: "bar"},
+					},
+				},
+				Else: &Expression{Type: Operand, GoValue: "none"},
+			},
+			Right: &Expression{Type: Operand, GoValue: "foobar"},
+		},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name string
+		expr *Expression
+		row  map[string]any
+	}{
+		{
+			name: "nested operators and case",
+			expr: compileTestExpression(),
+			row:  map[string]any{"foo": float64(30), "bar": "foobar", "baz": true},
+		},
+		{
+			name: "case falls back to else",
+			expr: compileTestExpression(),
+			row:  map[string]any{"foo": float64(30), "bar": "foobar", "baz": false},
+		},
+		{
+			name: "and short-circuits",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: And,
+				Left:     &Expression{Type: Operand, Identifier: "baz"},
+				Right:    &Expression{Type: Operand, Identifier: "foo"},
+			},
+			row: map[string]any{"foo": float64(1), "bar": "a", "baz": false},
+		},
+		{
+			name: "or short-circuits",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Or,
+				Left:     &Expression{Type: Operand, Identifier: "baz"},
+				Right:    &Expression{Type: Operand, Identifier: "foo"},
+			},
+			row: map[string]any{"foo": float64(1), "bar": "a", "baz": true},
+		},
+		{
+			name: "logical operation on a number",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Or,
+				Left:     &Expression{Type: Operand, Identifier: "baz"},
+				Right:    &Expression{Type: Operand, Identifier: "foo"},
+			},
+			row: map[string]any{"foo": float64(1), "bar": "a", "baz": false},
+		},
+		{
+			name: "comparison of different types",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: LessThan,
+				Left:     &Expression{Type: Operand, Identifier: "bar"},
+				Right:    &Expression{Type: Operand, Identifier: "foo"},
+			},
+			row: map[string]any{"foo": float64(1), "bar": "a", "baz": false},
+		},
+		{
+			name: "simple case",
+			expr: &Expression{
+				Type:    Case,
+				Subject: &Expression{Type: Operand, Identifier: "bar"},
+				Branches: []*CaseBranch{
+					{
+						When: &Expression{Type: Operand, GoValue: "a"},
+						Then: &Expression{Type: Operand, Identifier: "foo"},
+					},
+				},
+			},
+			row: map[string]any{"foo": float64(1), "bar": "a", "baz": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := Evaluate(tt.expr, tt.row)
+
+			program, err := Compile(tt.expr, compileTestColumns)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			got, err := program(rowValues(tt.row))
+			if (err != nil) != (wantErr != nil) {
+				t.Errorf("program error = %v, but Evaluate() error = %v", err, wantErr)
+				return
+			}
+
+			if wantErr == nil && !reflect.DeepEqual(got, want.GoValue) {
+				t.Errorf("program = %v, but Evaluate() = %v", got, want.GoValue)
+			}
+		})
+	}
+}
+
+func TestCompileUnknownIdentifier(t *testing.T) {
+	_, err := Compile(&Expression{Type: Operand, Identifier: "qux"}, compileTestColumns)
+	if err == nil || err.Error() != "value 'qux' does not exist" {
+		t.Errorf("expected unknown identifier error, but got '%v'", err)
+	}
+}
+
+func rowValues(row map[string]any) []any {
+	values := make([]any, len(compileTestColumns))
+	for i, c := range compileTestColumns {
+		values[i] = row[c]
+	}
+
+	return values
+}
+
+func benchmarkRows(n int) [][]any {
+	rows := make([][]any, n)
+	for i := range rows {
+		rows[i] = []any{float64(i % 50), "foobar", i%2 == 0}
+	}
+
+	return rows
+}
+
+// BenchmarkEvaluate mimics a scan before compilation, where a map is built
+// for each row and the tree is walked to evaluate it.
+func BenchmarkEvaluate(b *testing.B) {
+	expr := compileTestExpression()
+	rows := benchmarkRows(10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range rows {
+			m := make(map[string]any, len(compileTestColumns))
+			for j, c := range compileTestColumns {
+				m[c] = row[j]
+			}
+
+			if _, err := Evaluate(expr, m); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	program, err := Compile(compileTestExpression(), compileTestColumns)
+	if err != nil {
+		b.Fatal(err)
+	}
+	rows := benchmarkRows(10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range rows {
+			if _, err := program(row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package eval
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxDecimalPrecision is the amount of digits that always
+	// fit in the integer holding the value of a decimal.
+	MaxDecimalPrecision = 18
+
+	// divisionScale is the least amount of fractional digits kept when
+	// dividing decimals, the result is rounded half away from zero.
+	divisionScale = 6
+)
+
+var (
+	errDecimalOutOfRange = errors.New("decimal out of range")
+	errDivisionByZero    = errors.New("division by zero")
+
+	decimalRegexp = regexp.MustCompile(`^[+-]?\d+(\.\d+)?$`)
+)
+
+// Decimal is an exact number worth unscaled * 10^-scale. Additions and
+// subtractions are exact, multiplications are rounded to the largest scale
+// of their operands and divisions to at least divisionScale.
+type Decimal struct {
+	unscaled int64
+	scale    int
+}
+
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return Decimal{unscaled: unscaled, scale: scale}
+}
+
+// ParseDecimal parses numbers such as 12, -0.5 or 1234.5600, the amount
+// of fractional digits written is kept as the scale of the decimal.
+func ParseDecimal(s string) (Decimal, error) {
+	if !decimalRegexp.MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal '%s'", s)
+	}
+
+	integer, fraction, _ := strings.Cut(s, ".")
+	if len(fraction) > MaxDecimalPrecision {
+		return Decimal{}, errDecimalOutOfRange
+	}
+
+	unscaled, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return Decimal{}, errDecimalOutOfRange
+	}
+
+	d := Decimal{unscaled: unscaled, scale: len(fraction)}
+	if d.Digits() > MaxDecimalPrecision {
+		return Decimal{}, errDecimalOutOfRange
+	}
+
+	return d, nil
+}
+
+func (d Decimal) Unscaled() int64 {
+	return d.unscaled
+}
+
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Digits returns the amount of significant digits of the decimal,
+// counting every fractional digit even when they are zeros.
+func (d Decimal) Digits() int {
+	n := len(strconv.FormatUint(absInt64(d.unscaled), 10))
+	return max(n, d.scale)
+}
+
+// IntegerDigits returns the amount of digits before the decimal point.
+func (d Decimal) IntegerDigits() int {
+	if d.unscaled == 0 {
+		return 0
+	}
+
+	return max(d.Digits()-d.scale, 0)
+}
+
+func (d Decimal) String() string {
+	s := strconv.FormatUint(absInt64(d.unscaled), 10)
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+
+	if d.unscaled < 0 {
+		return "-" + s
+	}
+
+	return s
+}
+
+// MarshalJSON writes the decimal as a number with all of its digits,
+// so it isn't rounded the way a float would be.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Equal tells if both decimals are worth the same, regardless of their scale.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Compare(o) == 0
+}
+
+func (d Decimal) Compare(o Decimal) int {
+	scale := max(d.scale, o.scale)
+	return d.bigUnscaled(scale).Cmp(o.bigUnscaled(scale))
+}
+
+func (d Decimal) Float() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Round changes the scale of the decimal, digits that don't fit
+// in the new scale are rounded half away from zero.
+func (d Decimal) Round(scale int) (Decimal, error) {
+	if scale >= d.scale {
+		return d.rescale(scale)
+	}
+
+	return fromBig(roundedQuotient(big.NewInt(d.unscaled), pow10(d.scale-scale)), scale)
+}
+
+func (d Decimal) rescale(scale int) (Decimal, error) {
+	return fromBig(d.bigUnscaled(scale), scale)
+}
+
+// bigUnscaled returns the unscaled value of the decimal at
+// a scale that is greater than or equal to its own.
+func (d Decimal) bigUnscaled(scale int) *big.Int {
+	u := big.NewInt(d.unscaled)
+	return u.Mul(u, pow10(scale-d.scale))
+}
+
+func fromBig(unscaled *big.Int, scale int) (Decimal, error) {
+	if !unscaled.IsInt64() || scale > MaxDecimalPrecision {
+		return Decimal{}, errDecimalOutOfRange
+	}
+
+	d := Decimal{unscaled: unscaled.Int64(), scale: scale}
+	if d.Digits() > MaxDecimalPrecision {
+		return Decimal{}, errDecimalOutOfRange
+	}
+
+	return d, nil
+}
+
+// roundedQuotient divides n by d rounding half away from zero.
+func roundedQuotient(n, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+
+	// the remainder is at least half of the divisor
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(new(big.Int).Abs(d)) >= 0 {
+		if (n.Sign() < 0) != (d.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func absInt64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+
+	return uint64(v)
+}
+
+// asDecimal converts integers and decimals to a decimal,
+// it reports false for any other value.
+func asDecimal(v any) (Decimal, bool) {
+	switch n := v.(type) {
+	case Decimal:
+		return n, true
+	case int64:
+		return Decimal{unscaled: n}, true
+	}
+
+	return Decimal{}, false
+}
+
+// decimalArithmetic applies operator to two numbers when one of them is
+// a decimal and the other isn't a float, it reports false otherwise.
+func decimalArithmetic(operator OperatorType, left, right any) (any, bool, error) {
+	_, lok := left.(Decimal)
+	_, rok := right.(Decimal)
+	if !lok && !rok {
+		return nil, false, nil
+	}
+
+	l, lok := asDecimal(left)
+	r, rok := asDecimal(right)
+	if !lok || !rok {
+		return nil, false, nil
+	}
+
+	common := max(l.scale, r.scale)
+
+	var v Decimal
+	var err error
+	switch operator {
+	case Add:
+		v, err = fromBig(new(big.Int).Add(l.bigUnscaled(common), r.bigUnscaled(common)), common)
+	case Subtract:
+		v, err = fromBig(new(big.Int).Sub(l.bigUnscaled(common), r.bigUnscaled(common)), common)
+	case Multiply:
+		// the product has the scales of both operands added up,
+		// which wouldn't leave room for many integer digits
+		n := new(big.Int).Mul(big.NewInt(l.unscaled), big.NewInt(r.unscaled))
+		v, err = fromBig(roundedQuotient(n, pow10(l.scale+r.scale-common)), common)
+	case Divide:
+		if r.unscaled == 0 {
+			return nil, true, errDivisionByZero
+		}
+
+		// once both unscaled values are at the same scale, the
+		// quotient at the given scale is (l * 10^scale) / r
+		scale := max(common, divisionScale)
+		n := new(big.Int).Mul(l.bigUnscaled(common), pow10(scale))
+		v, err = fromBig(roundedQuotient(n, r.bigUnscaled(common)), scale)
+	default:
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, true, err
+	}
+
+	return v, true, nil
+}
+
+// compareDecimal compares a decimal with another number, floats are compared
+// with the decimal converted to a float, the same way they are operated on.
+func compareDecimal(left, right any) (int, bool) {
+	_, lok := left.(Decimal)
+	_, rok := right.(Decimal)
+	if !lok && !rok {
+		return 0, false
+	}
+
+	if l, ok := asDecimal(left); ok {
+		if r, ok := asDecimal(right); ok {
+			return l.Compare(r), true
+		}
+	}
+
+	l, lok := toFloat(left)
+	r, rok := toFloat(right)
+	if !lok || !rok {
+		return 0, false
+	}
+
+	return cmp.Compare(l, r), true
+}
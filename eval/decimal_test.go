@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Decimal
+		str     string
+		wantErr string
+	}{
+		{input: "12", want: NewDecimal(12, 0), str: "12"},
+		{input: "-0.05", want: NewDecimal(-5, 2), str: "-0.05"},
+		{input: "+1234.5600", want: NewDecimal(12345600, 4), str: "1234.5600"},
+		{input: "999999999999999999", want: NewDecimal(999999999999999999, 0), str: "999999999999999999"},
+		{input: "0.000000000000000001", want: NewDecimal(1, 18), str: "0.000000000000000001"},
+		{input: "1000000000000000000", wantErr: "decimal out of range"},
+		{input: "0.0000000000000000001", wantErr: "decimal out of range"},
+		{input: "1.", wantErr: "invalid decimal '1.'"},
+		{input: "1e3", wantErr: "invalid decimal '1e3'"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.input)
+		if err != nil {
+			if err.Error() != tt.wantErr {
+				t.Errorf("%s: unexpected error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			continue
+		}
+
+		if tt.wantErr != "" {
+			t.Errorf("%s: expected error %v", tt.input, tt.wantErr)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.input, got, tt.want)
+		}
+
+		if got.String() != tt.str {
+			t.Errorf("%s: String() = %s, want %s", tt.input, got.String(), tt.str)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator OperatorType
+		left     any
+		right    any
+		want     any
+		wantErr  string
+	}{
+		{
+			name:     "sums don't drift",
+			operator: Add,
+			left:     NewDecimal(1, 1),
+			right:    NewDecimal(2, 1),
+			want:     NewDecimal(3, 1),
+		},
+		{
+			name:     "keeps the largest scale",
+			operator: Subtract,
+			left:     NewDecimal(1999, 2),
+			right:    NewDecimal(5, 3),
+			want:     NewDecimal(19985, 3),
+		},
+		{
+			name:     "integers become decimals",
+			operator: Multiply,
+			left:     int64(3),
+			right:    NewDecimal(1999, 2),
+			want:     NewDecimal(5997, 2),
+		},
+		{
+			name:     "multiplication rounds to the largest scale",
+			operator: Multiply,
+			left:     NewDecimal(15, 1),
+			right:    NewDecimal(15, 2),
+			want:     NewDecimal(23, 2),
+		},
+		{
+			name:     "multiplication of large scales",
+			operator: Multiply,
+			left:     NewDecimal(1234567890, 4),
+			right:    NewDecimal(1234567890, 4),
+			want:     NewDecimal(152415787501905, 4),
+		},
+		{
+			name:     "division keeps at least six decimal places",
+			operator: Divide,
+			left:     NewDecimal(100, 2),
+			right:    int64(3),
+			want:     NewDecimal(333333, 6),
+		},
+		{
+			name:     "division rounds half away from zero",
+			operator: Divide,
+			left:     NewDecimal(-2, 0),
+			right:    NewDecimal(3, 0),
+			want:     NewDecimal(-666667, 6),
+		},
+		{
+			name:     "division by zero",
+			operator: Divide,
+			left:     NewDecimal(1, 0),
+			right:    int64(0),
+			wantErr:  "division by zero",
+		},
+		{
+			name:     "out of range",
+			operator: Multiply,
+			left:     NewDecimal(999999999999, 0),
+			right:    NewDecimal(9999999, 0),
+			wantErr:  "decimal out of range",
+		},
+		{
+			name:     "floats make the result a float",
+			operator: Add,
+			left:     NewDecimal(5, 1),
+			right:    0.25,
+			want:     0.75,
+		},
+		{
+			name:     "integer division truncates",
+			operator: Divide,
+			left:     int64(-7),
+			right:    int64(2),
+			want:     int64(-3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := binaryOperators[tt.operator](tt.left, tt.right)
+			if err != nil {
+				if err.Error() != tt.wantErr {
+					t.Errorf("unexpected error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if tt.wantErr != "" {
+				t.Errorf("expected error %v", tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		value Decimal
+		scale int
+		want  Decimal
+	}{
+		{value: NewDecimal(1005, 3), scale: 2, want: NewDecimal(101, 2)},
+		{value: NewDecimal(-1005, 3), scale: 2, want: NewDecimal(-101, 2)},
+		{value: NewDecimal(1004, 3), scale: 2, want: NewDecimal(100, 2)},
+		{value: NewDecimal(15, 1), scale: 0, want: NewDecimal(2, 0)},
+		{value: NewDecimal(15, 1), scale: 3, want: NewDecimal(1500, 3)},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.value.Round(tt.scale)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s rounded to %d = %s, want %s", tt.value, tt.scale, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// EnumType is a type whose values are one of a fixed set of labels.
+type EnumType struct {
+	Name   string
+	Labels []string
+}
+
+// Value returns the value of the type with the given label.
+func (t *EnumType) Value(label string) (Enum, error) {
+	i := slices.Index(t.Labels, label)
+	if i < 0 {
+		return Enum{}, fmt.Errorf("invalid value '%s' for enum '%s'", label, t.Name)
+	}
+
+	return Enum{t: t, index: i}, nil
+}
+
+// ValueAt returns the value of the type declared at the given position.
+func (t *EnumType) ValueAt(index int) (Enum, error) {
+	if index < 0 || index >= len(t.Labels) {
+		return Enum{}, fmt.Errorf("enum '%s' has no value at %d", t.Name, index)
+	}
+
+	return Enum{t: t, index: index}, nil
+}
+
+// Enum is a value of an enumerated type, values are ordered
+// by the position their labels were declared in.
+type Enum struct {
+	t     *EnumType
+	index int
+}
+
+func (e Enum) Type() *EnumType {
+	return e.t
+}
+
+func (e Enum) Index() int {
+	return e.index
+}
+
+func (e Enum) String() string {
+	return e.t.Labels[e.index]
+}
+
+func (e Enum) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// compareEnum compares values of the same enumerated type, or one of them
+// with a string, which must be a label of the type. Types are told apart by
+// their names, since a type read back from the schema is a copy of the one
+// its values were made with. It reports false when neither of them is an enum.
+func compareEnum(left, right any) (int, bool, error) {
+	l, lok := left.(Enum)
+	r, rok := right.(Enum)
+
+	switch {
+	case lok && rok:
+		if l.t.Name != r.t.Name {
+			return 0, true, fmt.Errorf("can't compare values of enums '%s' and '%s'", l.t.Name, r.t.Name)
+		}
+	case lok:
+		s, ok := right.(string)
+		if !ok {
+			return 0, false, nil
+		}
+
+		v, err := l.t.Value(s)
+		if err != nil {
+			return 0, true, err
+		}
+
+		r = v
+	case rok:
+		s, ok := left.(string)
+		if !ok {
+			return 0, false, nil
+		}
+
+		v, err := r.t.Value(s)
+		if err != nil {
+			return 0, true, err
+		}
+
+		l = v
+	default:
+		return 0, false, nil
+	}
+
+	return cmp.Compare(l.index, r.index), true, nil
+}
@@ -0,0 +1,51 @@
+package eval
+
+import "testing"
+
+func TestCompareEnum(t *testing.T) {
+	mood := &EnumType{Name: "mood", Labels: []string{"sad", "ok", "happy"}}
+	other := &EnumType{Name: "other", Labels: []string{"sad"}}
+	// the same type read back from where it's stored
+	copied := &EnumType{Name: "mood", Labels: []string{"sad", "ok", "happy"}}
+
+	value := func(typ *EnumType, label string) Enum {
+		v, err := typ.Value(label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		left, right any
+		want        int
+		wantErr     string
+	}{
+		{left: value(mood, "sad"), right: value(mood, "happy"), want: -1},
+		{left: value(mood, "happy"), right: value(mood, "ok"), want: 1},
+		{left: value(mood, "ok"), right: "ok", want: 0},
+		// declaration order, not alphabetical order
+		{left: "happy", right: value(mood, "sad"), want: 1},
+		{left: value(mood, "ok"), right: "angry", wantErr: "invalid value 'angry' for enum 'mood'"},
+		{left: value(mood, "ok"), right: value(copied, "happy"), want: -1},
+		{left: value(mood, "sad"), right: value(other, "sad"), wantErr: "can't compare values of enums 'mood' and 'other'"},
+		{left: value(mood, "sad"), right: int64(0), wantErr: "both sides of a comparison operation must be of the same type, but got 'enum' and 'number'"},
+	}
+
+	for i, tt := range tests {
+		got, err := Compare(tt.left, tt.right)
+		gotErr := ""
+		if err != nil {
+			gotErr = err.Error()
+		}
+
+		if gotErr != tt.wantErr {
+			t.Errorf("#%d expected error '%s', but got '%s'", i, tt.wantErr, gotErr)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("#%d expected %d, but got %d", i, tt.want, got)
+		}
+	}
+}
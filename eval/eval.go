@@ -1,9 +1,14 @@
 package eval
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type OperatorType string
@@ -18,9 +23,19 @@ const (
 	GreaterThan      OperatorType = "greater"
 	LessEqualThan    OperatorType = "less_equal"
 	LessThan         OperatorType = "less"
+	Add              OperatorType = "add"
+	Subtract         OperatorType = "subtract"
+	Multiply         OperatorType = "multiply"
+	Divide           OperatorType = "divide"
+	JSONExtract      OperatorType = "json_extract"
+	JSONExtractText  OperatorType = "json_extract_text"
+	Index            OperatorType = "index"
+	Contains         OperatorType = "contains"
+	ContainedBy      OperatorType = "contained_by"
+	Overlaps         OperatorType = "overlaps"
 )
 
-var operators = []OperatorType{And, Or, Equal, NotEqual, GreaterEqualThan, GreaterThan, LessEqualThan, LessThan}
+var operators = []OperatorType{And, Or, Equal, NotEqual, GreaterEqualThan, GreaterThan, LessEqualThan, LessThan, Add, Subtract, Multiply, Divide, JSONExtract, JSONExtractText, Index, Contains, ContainedBy, Overlaps}
 
 func IsOperator(operator string) bool {
 	return slices.Contains(operators, OperatorType(operator))
@@ -29,6 +44,8 @@ func IsOperator(operator string) bool {
 const (
 	Operator ExpressionType = "operator"
 	Operand  ExpressionType = "operand"
+	Case     ExpressionType = "case"
+	Function ExpressionType = "function"
 )
 
 type Expression struct {
@@ -40,20 +57,61 @@ type Expression struct {
 
 	Left  *Expression
 	Right *Expression
+
+	// CASE expressions, Subject is only set for the simple form,
+	// where each branch is compared against it.
+	Subject  *Expression
+	Branches []*CaseBranch
+	Else     *Expression
+
+	// function calls
+	Function string
+	Args     []*Expression
+
+	// position in the query used to report semantic errors, where the
+	// expression starts, except for operators, which keep their own token
+	// so an error points at the operation that failed
+	Line   int
+	Column int
+}
+
+type CaseBranch struct {
+	When *Expression
+	Then *Expression
 }
 
 type EvalResult struct {
 	GoValue any
 }
 
-func (r *EvalResult) genericValueType() string {
-	switch r.GoValue.(type) {
-	case float64:
-		return "number"
+func genericValueType(v any) ValueType {
+	switch t := v.(type) {
+	case int64, float64, Decimal:
+		return NumberValue
 	case bool:
-		return "bool"
+		return BoolValue
 	case string:
-		return "string"
+		return StringValue
+	case []byte:
+		return BytesValue
+	case uuid.UUID:
+		return UUIDValue
+	case JSON:
+		return JSONValue
+	case Enum:
+		return EnumValue
+	case Array:
+		return arrayType(t)
+	case anyElement:
+		return anyOf(arrayType(Array(t)))
+	case Date:
+		return DateValue
+	case TimeOfDay:
+		return TimeValue
+	case time.Time:
+		return TimestampValue
+	case Interval:
+		return IntervalValue
 	default:
 		return ""
 	}
@@ -81,40 +139,96 @@ func Evaluate(expr *Expression, values map[string]any) (*EvalResult, error) {
 		}
 	}
 
+	if expr.Type == Case {
+		return expr.evaluateCase(values)
+	}
+
+	if expr.Type == Function {
+		return expr.evaluateFunction(values)
+	}
+
 	if expr.Type == Operator {
 		switch expr.Operator {
 		case And:
 			return expr.evaluateAnd(values)
 		case Or:
 			return expr.evaluateOr(values)
-		case Equal:
-			return expr.evaluateEqual(values)
-		case NotEqual:
-			return expr.evaluateNotEqual(values)
-		case GreaterThan:
-			return expr.evaluateGreater(values)
-		case GreaterEqualThan:
-			return expr.evaluateGreaterEqual(values)
-		case LessThan:
-			return expr.evaluateLess(values)
-		case LessEqualThan:
-			return expr.evaluateLessEqual(values)
+		}
+
+		if op, ok := binaryOperators[expr.Operator]; ok {
+			return expr.evaluateBinary(values, op)
 		}
 	}
 
 	return nil, errors.New("unknown expression type")
 }
 
-func greaterThan(left, right any) bool {
-	l := left.(float64)
-	r := right.(float64)
+// Compare returns -1, 0 or +1 depending on whether left is less than, equal
+// to or greater than right. Both values must be of the same type, strings and
+// bytes are ordered byte-wise and false is ordered before true.
+func Compare(left, right any) (int, error) {
+	if c, ok := compareNumbers(left, right); ok {
+		return c, nil
+	}
+
+	if c, ok := compareTemporal(left, right); ok {
+		return c, nil
+	}
+
+	if c, ok, err := compareEnum(left, right); ok {
+		return c, err
+	}
+
+	if c, ok, err := compareArrays(left, right); ok {
+		return c, err
+	}
+
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			return compareBool(l, r), nil
+		}
+	case []byte:
+		if r, ok := right.([]byte); ok {
+			return bytes.Compare(l, r), nil
+		}
+	case uuid.UUID:
+		if r, ok := right.(uuid.UUID); ok {
+			return bytes.Compare(l[:], r[:]), nil
+		}
+	}
+
+	return 0, fmt.Errorf("both sides of a comparison operation must be of the same type, but got '%s' and '%s'", genericValueType(left), genericValueType(right))
+}
+
+func compareBool(l, r bool) int {
+	if l == r {
+		return 0
+	}
+
+	if !l {
+		return -1
+	}
 
-	return l > r
+	return 1
 }
 
-func greaterOrEqualThan(left, right any) bool {
-	l := left.(float64)
-	r := right.(float64)
+// equal compares values that can be ordered by their value regardless of
+// their representation, every other value must match exactly.
+func equal(left, right any) bool {
+	if c, err := Compare(left, right); err == nil {
+		return c == 0
+	}
+
+	// arrays can't be compared with ==, and they're only
+	// equal when their elements can be compared
+	if _, ok := left.(Array); ok {
+		return false
+	}
 
-	return l >= r
+	return left == right
 }
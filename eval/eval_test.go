@@ -1,8 +1,10 @@
 package eval
 
 import (
+	"math"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_Evaluate(t *testing.T) {
@@ -184,6 +186,267 @@ func Test_Evaluate(t *testing.T) {
 				GoValue: false,
 			},
 		},
+		{
+			name: "greater or equal with equal values",
+			args: args{
+				row: map[string]any{
+					"foo": float64(123),
+					"bar": float64(123),
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "greater_equal",
+					Left:     &Expression{Type: Operand, Identifier: "bar"},
+					Right:    &Expression{Type: Operand, Identifier: "foo"},
+				},
+			},
+			want: &EvalResult{
+				GoValue: true,
+			},
+		},
+		{
+			name: "integers and floats are equal by value",
+			args: args{
+				row: map[string]any{
+					"foo": int64(23),
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "and",
+					Left: &Expression{
+						Type:     Operator,
+						Operator: "equal",
+						Left:     &Expression{Type: Operand, Identifier: "foo"},
+						Right:    &Expression{Type: Operand, GoValue: float64(23)},
+					},
+					Right: &Expression{
+						Type:     Operator,
+						Operator: "not_equal",
+						Left:     &Expression{Type: Operand, Identifier: "foo"},
+						Right:    &Expression{Type: Operand, GoValue: float64(23.5)},
+					},
+				},
+			},
+			want: &EvalResult{
+				GoValue: true,
+			},
+		},
+		{
+			name: "large integers are compared exactly",
+			args: args{
+				row: map[string]any{
+					"foo": int64(1700000000000000001),
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "equal",
+					Left:     &Expression{Type: Operand, Identifier: "foo"},
+					Right:    &Expression{Type: Operand, GoValue: int64(1700000000000000000)},
+				},
+			},
+			want: &EvalResult{
+				GoValue: false,
+			},
+		},
+		{
+			name: "strings are ordered byte-wise",
+			args: args{
+				row: map[string]any{
+					"name": "mary",
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "and",
+					Left: &Expression{
+						Type:     Operator,
+						Operator: "greater_equal",
+						Left:     &Expression{Type: Operand, Identifier: "name"},
+						Right:    &Expression{Type: Operand, GoValue: "m"},
+					},
+					Right: &Expression{
+						Type:     Operator,
+						Operator: "less",
+						Left:     &Expression{Type: Operand, Identifier: "name"},
+						Right:    &Expression{Type: Operand, GoValue: "n"},
+					},
+				},
+			},
+			want: &EvalResult{
+				GoValue: true,
+			},
+		},
+		{
+			name: "uppercase is ordered before lowercase",
+			args: args{
+				row: map[string]any{
+					"name": "Zoe",
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "greater",
+					Left:     &Expression{Type: Operand, Identifier: "name"},
+					Right:    &Expression{Type: Operand, GoValue: "adam"},
+				},
+			},
+			want: &EvalResult{
+				GoValue: false,
+			},
+		},
+		{
+			name: "false is ordered before true",
+			args: args{
+				row: map[string]any{
+					"foo": false,
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "less_equal",
+					Left:     &Expression{Type: Operand, Identifier: "foo"},
+					Right:    &Expression{Type: Operand, GoValue: true},
+				},
+			},
+			want: &EvalResult{
+				GoValue: true,
+			},
+		},
+		{
+			name: "ordering values of different types",
+			args: args{
+				row: map[string]any{
+					"foo": "123",
+				},
+				expr: &Expression{
+					Type:     Operator,
+					Operator: "greater",
+					Left:     &Expression{Type: Operand, Identifier: "foo"},
+					Right:    &Expression{Type: Operand, GoValue: float64(1)},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "searched case",
+			args: args{
+				row: map[string]any{
+					"foo": float64(7),
+				},
+				expr: &Expression{
+					Type: Case,
+					Branches: []*CaseBranch{
+						{
+							When: &Expression{
+								Type:     Operator,
+								Operator: "greater",
+								Left:     &Expression{Type: Operand, Identifier: "foo"},
+								Right:    &Expression{Type: Operand, GoValue: float64(10)},
+							},
+							Then: &Expression{Type: Operand, GoValue: "big"},
+						},
+						{
+							When: &Expression{
+								Type:     Operator,
+								Operator: "greater",
+								Left:     &Expression{Type: Operand, Identifier: "foo"},
+								Right:    &Expression{Type: Operand, GoValue: float64(5)},
+							},
+							Then: &Expression{Type: Operand, GoValue: "medium"},
+						},
+					},
+					Else: &Expression{Type: Operand, GoValue: "small"},
+				},
+			},
+			want: &EvalResult{
+				GoValue: "medium",
+			},
+		},
+		{
+			name: "simple case falls back to else",
+			args: args{
+				row: map[string]any{
+					"foo": "c",
+				},
+				expr: &Expression{
+					Type:    Case,
+					Subject: &Expression{Type: Operand, Identifier: "foo"},
+					Branches: []*CaseBranch{
+						{
+							When: &Expression{Type: Operand, GoValue: "a"},
+							Then: &Expression{Type: Operand, GoValue: float64(1)},
+						},
+						{
+							When: &Expression{Type: Operand, GoValue: "b"},
+							Then: &Expression{Type: Operand, GoValue: float64(2)},
+						},
+					},
+					Else: &Expression{Type: Operand, GoValue: float64(0)},
+				},
+			},
+			want: &EvalResult{
+				GoValue: float64(0),
+			},
+		},
+		{
+			name: "case without else and no matching branch",
+			args: args{
+				row: map[string]any{
+					"foo": "c",
+				},
+				expr: &Expression{
+					Type:    Case,
+					Subject: &Expression{Type: Operand, Identifier: "foo"},
+					Branches: []*CaseBranch{
+						{
+							When: &Expression{Type: Operand, GoValue: "a"},
+							Then: &Expression{Type: Operand, GoValue: float64(1)},
+						},
+					},
+				},
+			},
+			want: &EvalResult{},
+		},
+		{
+			name: "case only evaluates the chosen branch",
+			args: args{
+				row: map[string]any{
+					"foo": true,
+				},
+				expr: &Expression{
+					Type: Case,
+					Branches: []*CaseBranch{
+						{
+							When: &Expression{Type: Operand, Identifier: "foo"},
+							Then: &Expression{Type: Operand, GoValue: "ok"},
+						},
+						{
+							When: &Expression{Type: Operand, Identifier: "missing"},
+							Then: &Expression{Type: Operand, Identifier: "missing"},
+						},
+					},
+					Else: &Expression{Type: Operand, Identifier: "missing"},
+				},
+			},
+			want: &EvalResult{
+				GoValue: "ok",
+			},
+		},
+		{
+			name: "case with non boolean condition",
+			args: args{
+				row: map[string]any{
+					"foo": "a",
+				},
+				expr: &Expression{
+					Type: Case,
+					Branches: []*CaseBranch{
+						{
+							When: &Expression{Type: Operand, Identifier: "foo"},
+							Then: &Expression{Type: Operand, GoValue: "ok"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -198,3 +461,95 @@ func Test_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		left, right any
+		want        int
+		wantErr     bool
+	}{
+		{left: float64(1), right: float64(2), want: -1},
+		{left: float64(2), right: float64(2), want: 0},
+		{left: "b", right: "a", want: 1},
+		{left: "a", right: "ab", want: -1},
+		{left: "", right: "", want: 0},
+		{left: false, right: true, want: -1},
+		{left: true, right: false, want: 1},
+		{left: true, right: true, want: 0},
+		{left: int64(1), right: int64(2), want: -1},
+		{left: int64(2), right: float64(2), want: 0},
+		{left: float64(2.5), right: int64(2), want: 1},
+		{left: int64(-3), right: float64(-2.5), want: -1},
+		{left: int64(9007199254740993), right: float64(9007199254740992), want: 1},
+		{left: int64(math.MaxInt64), right: float64(math.MaxInt64), want: -1},
+		{left: int64(math.MinInt64), right: float64(math.MinInt64), want: 0},
+		{left: int64(1), right: math.NaN(), want: 1},
+		{left: NewDecimal(10, 1), right: NewDecimal(100, 2), want: 0},
+		{left: NewDecimal(-5, 1), right: int64(0), want: -1},
+		{left: int64(math.MaxInt64), right: NewDecimal(999999999999999999, 0), want: 1},
+		{left: NewDecimal(1, 1), right: float64(0.1), want: 0},
+		{left: NewDecimal(1, 0), right: "1", wantErr: true},
+		{left: Interval{Months: 1}, right: Interval{Months: 1}, want: 0},
+		{left: Interval{Months: 1}, right: Interval{Days: 30}, want: 1},
+		{left: Interval{Days: 1}, right: Interval{Duration: 24 * time.Hour}, want: 1},
+		{left: Interval{Days: 1}, right: Interval{Duration: 25 * time.Hour}, want: -1},
+		{left: []byte{0xde, 0xad}, right: []byte{0xde, 0xad}, want: 0},
+		{left: []byte{0xde}, right: []byte{0xde, 0x00}, want: -1},
+		{left: []byte{0xff}, right: []byte{0x00, 0xff}, want: 1},
+		{left: []byte("a"), right: "a", wantErr: true},
+		{left: "true", right: true, wantErr: true},
+		{left: int64(1), right: "1", wantErr: true},
+		{left: nil, right: float64(1), wantErr: true},
+	}
+
+	for i, tt := range tests {
+		got, err := Compare(tt.left, tt.right)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("test %d failed: Compare() error = %v, wantErr %v", i+1, err, tt.wantErr)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("test %d failed: Compare(%v, %v) = %d, want %d", i+1, tt.left, tt.right, got, tt.want)
+		}
+	}
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		operator OperatorType
+		l, r     int64
+		want     int64
+		wantErr  string
+	}{
+		{operator: Add, l: math.MaxInt64 - 1, r: 1, want: math.MaxInt64},
+		{operator: Add, l: math.MaxInt64, r: 1, wantErr: "integer out of range"},
+		{operator: Add, l: math.MinInt64, r: -1, wantErr: "integer out of range"},
+		{operator: Subtract, l: -1, r: math.MinInt64, want: math.MaxInt64},
+		{operator: Subtract, l: 0, r: math.MinInt64, wantErr: "integer out of range"},
+		{operator: Subtract, l: math.MinInt64, r: 1, wantErr: "integer out of range"},
+		{operator: Subtract, l: math.MinInt64, r: 0, want: math.MinInt64},
+		{operator: Subtract, l: math.MaxInt64, r: -1, wantErr: "integer out of range"},
+		{operator: Multiply, l: math.MinInt64, r: -1, wantErr: "integer out of range"},
+		{operator: Multiply, l: math.MinInt64, r: 1, want: math.MinInt64},
+		{operator: Divide, l: math.MinInt64, r: -1, wantErr: "integer out of range"},
+		{operator: Divide, l: -7, r: 2, want: -3},
+	}
+
+	for i, tt := range tests {
+		got, err := integerArithmetic(tt.operator, tt.l, tt.r)
+		gotErr := ""
+		if err != nil {
+			gotErr = err.Error()
+		}
+
+		if gotErr != tt.wantErr {
+			t.Errorf("#%d expected error '%s', but got '%s'", i, tt.wantErr, gotErr)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("#%d expected %d, but got %d", i, tt.want, got)
+		}
+	}
+}
@@ -1,110 +1,164 @@
 package eval
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
+
+type binaryOperator func(left, right any) (any, error)
+
+// binaryOperators holds the operators that always need both of their
+// operands, logical operators short-circuit and are handled on their own.
+var binaryOperators = map[OperatorType]binaryOperator{
+	Equal: quantified(func(left, right any) (any, error) {
+		return equal(left, right), nil
+	}),
+	NotEqual: quantified(func(left, right any) (any, error) {
+		return !equal(left, right), nil
+	}),
+	GreaterThan:      quantified(comparison(func(c int) bool { return c > 0 })),
+	GreaterEqualThan: quantified(comparison(func(c int) bool { return c >= 0 })),
+	LessThan:         quantified(comparison(func(c int) bool { return c < 0 })),
+	LessEqualThan:    quantified(comparison(func(c int) bool { return c <= 0 })),
+	Add:              arithmetic(Add),
+	Subtract:         arithmetic(Subtract),
+	Multiply:         arithmetic(Multiply),
+	Divide:           arithmetic(Divide),
+	JSONExtract: func(left, right any) (any, error) {
+		return jsonExtract(left, []any{right}, false)
+	},
+	JSONExtractText: func(left, right any) (any, error) {
+		return jsonExtract(left, []any{right}, true)
+	},
+	Index:    arrayIndex,
+	Contains: contains,
+	ContainedBy: func(left, right any) (any, error) {
+		return contains(right, left)
+	},
+	Overlaps: overlaps,
+}
 
-func (expr *Expression) evaluateAnd(values map[string]any) (*EvalResult, error) {
-	left, err := Evaluate(expr.Left, values)
-	if err != nil {
-		return nil, err
-	}
+// arithmetic handles the operators applied to numbers, along with additions
+// and subtractions of intervals to or from dates and times.
+func arithmetic(operator OperatorType) binaryOperator {
+	return func(left, right any) (any, error) {
+		if v, ok, err := numberArithmetic(operator, left, right); ok {
+			return v, err
+		}
 
-	l, ok := left.GoValue.(bool)
-	if !ok {
-		return nil, errors.New("both sides of an logical operation must be boolean values")
-	}
+		if operator == Add || operator == Subtract {
+			if v, ok := addTemporal(left, right, operator == Subtract); ok {
+				return v, nil
+			}
+		}
 
-	if !l {
-		return &EvalResult{GoValue: false}, nil
-	}
+		if _, ok := left.(Date); ok && genericValueType(right) == NumberValue {
+			return nil, fmt.Errorf("dates can only be shifted by whole days, but got %v", right)
+		}
 
-	right, err := Evaluate(expr.Right, values)
-	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("can't %s '%s' and '%s'", operator, genericValueType(left), genericValueType(right))
 	}
+}
 
-	r, ok := right.GoValue.(bool)
-	if !ok {
-		return nil, errors.New("both sides of an logical operation must be boolean values")
-	}
+// comparison orders the operands with Compare, nothing, as given by a json
+// path that doesn't exist, isn't ordered against anything, like it isn't
+// equal to anything, so the comparison is false.
+func comparison(test func(int) bool) binaryOperator {
+	return func(left, right any) (any, error) {
+		if left == nil || right == nil {
+			return false, nil
+		}
 
-	return &EvalResult{GoValue: l && r}, nil
-}
+		c, err := Compare(left, right)
+		if err != nil {
+			return nil, err
+		}
 
-func (expr *Expression) evaluateOr(values map[string]any) (*EvalResult, error) {
-	left, err := Evaluate(expr.Left, values)
-	if err != nil {
-		return nil, err
+		return test(c), nil
 	}
+}
 
-	l, ok := left.GoValue.(bool)
+func logicalOperand(v any) (bool, error) {
+	b, ok := v.(bool)
 	if !ok {
-		return nil, errors.New("both sides of an logical operation must be boolean values")
+		return false, errors.New("both sides of an logical operation must be boolean values")
 	}
 
-	if l {
-		return &EvalResult{GoValue: true}, nil
-	}
+	return b, nil
+}
 
-	right, err := Evaluate(expr.Right, values)
-	if err != nil {
-		return nil, err
+// caseMatches tells if a CASE branch must be taken, when there's no
+// subject the WHEN value is the condition itself.
+func caseMatches(hasSubject bool, subject, when any) (bool, error) {
+	if hasSubject {
+		return equal(subject, when), nil
 	}
 
-	r, ok := right.GoValue.(bool)
+	v, ok := when.(bool)
 	if !ok {
-		return nil, errors.New("both sides of an logical operation must be boolean values")
+		return false, errors.New("WHEN condition of a CASE expression must be a boolean value")
 	}
 
-	return &EvalResult{GoValue: l || r}, nil
+	return v, nil
 }
 
-func (expr *Expression) evaluateEqual(values map[string]any) (*EvalResult, error) {
+func (expr *Expression) evaluateAnd(values map[string]any) (*EvalResult, error) {
 	left, err := Evaluate(expr.Left, values)
 	if err != nil {
 		return nil, err
 	}
 
-	right, err := Evaluate(expr.Right, values)
+	l, err := logicalOperand(left.GoValue)
 	if err != nil {
 		return nil, err
 	}
 
-	return &EvalResult{GoValue: left.GoValue == right.GoValue}, nil
-}
+	if !l {
+		return &EvalResult{GoValue: false}, nil
+	}
 
-func (expr *Expression) evaluateNotEqual(values map[string]any) (*EvalResult, error) {
-	left, err := Evaluate(expr.Left, values)
+	right, err := Evaluate(expr.Right, values)
 	if err != nil {
 		return nil, err
 	}
 
-	right, err := Evaluate(expr.Right, values)
+	r, err := logicalOperand(right.GoValue)
 	if err != nil {
 		return nil, err
 	}
 
-	return &EvalResult{GoValue: left.GoValue != right.GoValue}, nil
+	return &EvalResult{GoValue: l && r}, nil
 }
 
-func (expr *Expression) evaluateGreater(values map[string]any) (*EvalResult, error) {
+func (expr *Expression) evaluateOr(values map[string]any) (*EvalResult, error) {
 	left, err := Evaluate(expr.Left, values)
 	if err != nil {
 		return nil, err
 	}
 
+	l, err := logicalOperand(left.GoValue)
+	if err != nil {
+		return nil, err
+	}
+
+	if l {
+		return &EvalResult{GoValue: true}, nil
+	}
+
 	right, err := Evaluate(expr.Right, values)
 	if err != nil {
 		return nil, err
 	}
 
-	if !(left.genericValueType() == "number" && left.genericValueType() == right.genericValueType()) {
-		return nil, errors.New("both sides of a comparison operation must be numbers")
+	r, err := logicalOperand(right.GoValue)
+	if err != nil {
+		return nil, err
 	}
 
-	return &EvalResult{GoValue: greaterThan(left.GoValue, right.GoValue)}, nil
+	return &EvalResult{GoValue: l || r}, nil
 }
 
-func (expr *Expression) evaluateGreaterEqual(values map[string]any) (*EvalResult, error) {
+func (expr *Expression) evaluateBinary(values map[string]any, op binaryOperator) (*EvalResult, error) {
 	left, err := Evaluate(expr.Left, values)
 	if err != nil {
 		return nil, err
@@ -115,45 +169,68 @@ func (expr *Expression) evaluateGreaterEqual(values map[string]any) (*EvalResult
 		return nil, err
 	}
 
-	if !(left.genericValueType() == "number" && left.genericValueType() == right.genericValueType()) {
-		return nil, errors.New("both sides of a comparison operation must be numbers")
+	v, err := op(left.GoValue, right.GoValue)
+	if err != nil {
+		return nil, err
 	}
 
-	return &EvalResult{GoValue: greaterThan(left.GoValue, right.GoValue)}, nil
+	return &EvalResult{GoValue: v}, nil
 }
 
-func (expr *Expression) evaluateLess(values map[string]any) (*EvalResult, error) {
-	left, err := Evaluate(expr.Left, values)
-	if err != nil {
-		return nil, err
+// evaluateCase only evaluates the branches needed to reach a result,
+// if no branch matches and there's no ELSE, the result has no value.
+func (expr *Expression) evaluateCase(values map[string]any) (*EvalResult, error) {
+	var subject any
+	if expr.Subject != nil {
+		s, err := Evaluate(expr.Subject, values)
+		if err != nil {
+			return nil, err
+		}
+		subject = s.GoValue
 	}
 
-	right, err := Evaluate(expr.Right, values)
-	if err != nil {
-		return nil, err
+	for _, b := range expr.Branches {
+		when, err := Evaluate(b.When, values)
+		if err != nil {
+			return nil, err
+		}
+
+		matched, err := caseMatches(expr.Subject != nil, subject, when.GoValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			return Evaluate(b.Then, values)
+		}
 	}
 
-	if !(left.genericValueType() == "number" && left.genericValueType() == right.genericValueType()) {
-		return nil, errors.New("both sides of a comparison operation must be numbers")
+	if expr.Else != nil {
+		return Evaluate(expr.Else, values)
 	}
 
-	return &EvalResult{GoValue: greaterThan(right.GoValue, left.GoValue)}, nil
+	return &EvalResult{}, nil
 }
 
-func (expr *Expression) evaluateLessEqual(values map[string]any) (*EvalResult, error) {
-	left, err := Evaluate(expr.Left, values)
-	if err != nil {
-		return nil, err
+func (expr *Expression) evaluateFunction(values map[string]any) (*EvalResult, error) {
+	f, ok := functions[expr.Function]
+	if !ok {
+		return nil, fmt.Errorf("function '%s' does not exist", expr.Function)
 	}
 
-	right, err := Evaluate(expr.Right, values)
-	if err != nil {
-		return nil, err
+	args := make([]any, len(expr.Args))
+	for i, a := range expr.Args {
+		r, err := Evaluate(a, values)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = r.GoValue
 	}
 
-	if !(left.genericValueType() == "number" && left.genericValueType() == right.genericValueType()) {
-		return nil, errors.New("both sides of a comparison operation must be numbers")
+	v, err := f.call(args)
+	if err != nil {
+		return nil, err
 	}
 
-	return &EvalResult{GoValue: greaterOrEqualThan(right.GoValue, left.GoValue)}, nil
+	return &EvalResult{GoValue: v}, nil
 }
@@ -0,0 +1,359 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type function struct {
+	// returns checks the type of the arguments and gives
+	// the type of the value the function results in
+	returns func(args []ValueType) (ValueType, error)
+	call    func(args []any) (any, error)
+
+	// volatile functions may give a different result each time they
+	// are called with the same arguments, so they are never folded
+	volatile bool
+}
+
+var functions = map[string]*function{
+	"now": {
+		returns: noArguments("now", TimestampValue),
+		call: func([]any) (any, error) {
+			return time.Now().UTC().Truncate(time.Microsecond), nil
+		},
+		volatile: true,
+	},
+	"date_trunc": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) != 2 || args[0] != StringValue || (args[1] != TimestampValue && args[1] != DateValue) {
+				return "", errors.New("function 'date_trunc' expects a unit and a timestamp or date")
+			}
+
+			return TimestampValue, nil
+		},
+		call: func(args []any) (any, error) {
+			if len(args) != 2 {
+				return nil, errors.New("function 'date_trunc' expects a unit and a timestamp or date")
+			}
+
+			unit, ok := args[0].(string)
+			t, tok := asTimestamp(args[1])
+			if !ok || !tok {
+				return nil, errors.New("function 'date_trunc' expects a unit and a timestamp or date")
+			}
+
+			return dateTrunc(strings.ToLower(unit), t)
+		},
+	},
+	"extract": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) != 2 || args[0] != StringValue || (args[1] != TimestampValue && args[1] != DateValue && args[1] != TimeValue) {
+				return "", errors.New("function 'extract' expects a field and a timestamp, date or time")
+			}
+
+			return NumberValue, nil
+		},
+		call: func(args []any) (any, error) {
+			if len(args) != 2 {
+				return nil, errors.New("function 'extract' expects a field and a timestamp, date or time")
+			}
+
+			field, ok := args[0].(string)
+			if !ok {
+				return nil, errors.New("function 'extract' expects a field and a timestamp, date or time")
+			}
+
+			return extract(strings.ToLower(field), args[1])
+		},
+	},
+	"gen_random_uuid": {
+		returns: noArguments("gen_random_uuid", UUIDValue),
+		call: func([]any) (any, error) {
+			return uuid.NewRandom()
+		},
+		volatile: true,
+	},
+	"uuidv7": {
+		// version 7 identifiers start with a timestamp,
+		// so they sort in the order they were generated
+		returns: noArguments("uuidv7", UUIDValue),
+		call: func([]any) (any, error) {
+			return uuid.NewV7()
+		},
+		volatile: true,
+	},
+	"json_typeof": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) != 1 || args[0] != JSONValue {
+				return "", errors.New("function 'json_typeof' expects a json value")
+			}
+
+			return StringValue, nil
+		},
+		call: func(args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, errors.New("function 'json_typeof' expects a json value")
+			}
+
+			j, ok := args[0].(JSON)
+			if !ok {
+				return nil, errors.New("function 'json_typeof' expects a json value")
+			}
+
+			return j.Type(), nil
+		},
+	},
+	"json_array_length": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) != 1 || args[0] != JSONValue {
+				return "", errors.New("function 'json_array_length' expects a json array")
+			}
+
+			return NumberValue, nil
+		},
+		call: func(args []any) (any, error) {
+			if len(args) != 1 {
+				return nil, errors.New("function 'json_array_length' expects a json array")
+			}
+
+			return jsonArrayLength(args[0])
+		},
+	},
+	"array": {
+		// array literals, such as [1, 2, 3]
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) == 0 {
+				return ArrayOf(""), nil
+			}
+
+			for _, t := range args {
+				if _, ok := ElementType(t); ok {
+					return "", errors.New("arrays can't be nested")
+				}
+
+				if t != args[0] {
+					return "", fmt.Errorf("all elements of an array must be of the same type, but got '%s' and '%s'", args[0], t)
+				}
+			}
+
+			return ArrayOf(args[0]), nil
+		},
+		call: func(args []any) (any, error) {
+			return Array(slices.Clone(args)), nil
+		},
+	},
+	"any": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) == 1 {
+				if t, ok := ElementType(args[0]); ok {
+					return anyOf(t), nil
+				}
+			}
+
+			return "", errors.New("function 'any' expects an array")
+		},
+		call: func(args []any) (any, error) {
+			if len(args) == 1 {
+				if a, ok := args[0].(Array); ok {
+					return anyElement(a), nil
+				}
+			}
+
+			return nil, errors.New("function 'any' expects an array")
+		},
+	},
+	"cardinality": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) == 1 {
+				if _, ok := ElementType(args[0]); ok {
+					return NumberValue, nil
+				}
+			}
+
+			return "", errors.New("function 'cardinality' expects an array")
+		},
+		call: func(args []any) (any, error) {
+			if len(args) == 1 {
+				if a, ok := args[0].(Array); ok {
+					return int64(len(a)), nil
+				}
+			}
+
+			return nil, errors.New("function 'cardinality' expects an array")
+		},
+	},
+	"json_extract_path":      jsonExtractPath("json_extract_path", false),
+	"json_extract_path_text": jsonExtractPath("json_extract_path_text", true),
+	"round": {
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) < 1 || len(args) > 2 || args[0] != NumberValue || (len(args) == 2 && args[1] != NumberValue) {
+				return "", errors.New("function 'round' expects a number and optionally the amount of decimal places")
+			}
+
+			return NumberValue, nil
+		},
+		call: func(args []any) (any, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, errors.New("function 'round' expects a number and optionally the amount of decimal places")
+			}
+
+			places := int64(0)
+			if len(args) == 2 {
+				p, ok := args[1].(int64)
+				if !ok {
+					return nil, errors.New("function 'round' expects an integer amount of decimal places")
+				}
+				places = p
+			}
+
+			return round(args[0], places)
+		},
+	},
+}
+
+func noArguments(name string, result ValueType) func([]ValueType) (ValueType, error) {
+	return func(args []ValueType) (ValueType, error) {
+		if len(args) != 0 {
+			return "", fmt.Errorf("function '%s' doesn't take arguments", name)
+		}
+
+		return result, nil
+	}
+}
+
+// jsonExtractPath follows as many keys and indexes as it's given,
+// doc->"a"->0 is the same as json_extract_path(doc, "a", 0).
+func jsonExtractPath(name string, asText bool) *function {
+	result := JSONValue
+	if asText {
+		result = StringValue
+	}
+
+	return &function{
+		returns: func(args []ValueType) (ValueType, error) {
+			if len(args) < 2 || args[0] != JSONValue {
+				return "", fmt.Errorf("function '%s' expects a json value followed by its path", name)
+			}
+
+			for _, a := range args[1:] {
+				if a != StringValue && a != NumberValue {
+					return "", fmt.Errorf("function '%s' expects path elements to be strings or numbers, but got '%s'", name, a)
+				}
+			}
+
+			return result, nil
+		},
+		call: func(args []any) (any, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("function '%s' expects a json value followed by its path", name)
+			}
+
+			return jsonExtract(args[0], args[1:], asText)
+		},
+	}
+}
+
+// round rounds half away from zero, integers are kept as they are
+// and decimals are given the requested scale.
+func round(v any, places int64) (any, error) {
+	if places < 0 || places > MaxDecimalPrecision {
+		return nil, fmt.Errorf("decimal places must be between 0 and %d, but got %d", MaxDecimalPrecision, places)
+	}
+
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case Decimal:
+		return n.Round(int(places))
+	case float64:
+		p := math.Pow10(int(places))
+		return math.Round(n*p) / p, nil
+	}
+
+	return nil, errors.New("function 'round' expects a number and optionally the amount of decimal places")
+}
+
+func dateTrunc(unit string, t time.Time) (time.Time, error) {
+	switch unit {
+	case "microsecond":
+		return t.Truncate(time.Microsecond), nil
+	case "millisecond":
+		return t.Truncate(time.Millisecond), nil
+	case "second":
+		return t.Truncate(time.Second), nil
+	case "minute":
+		return t.Truncate(time.Minute), nil
+	case "hour":
+		return t.Truncate(time.Hour), nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	case "week":
+		// weeks start on monday, as in ISO-8601
+		d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7)), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case "quarter":
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC), nil
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unit '%s' is not supported by date_trunc", unit)
+}
+
+func extract(field string, v any) (any, error) {
+	if t, ok := v.(TimeOfDay); ok {
+		d := time.Duration(t)
+		switch field {
+		case "hour":
+			return int64(d / time.Hour), nil
+		case "minute":
+			return int64(d % time.Hour / time.Minute), nil
+		case "second":
+			return (d % time.Minute).Seconds(), nil
+		}
+
+		return nil, fmt.Errorf("field '%s' can't be extracted from a time", field)
+	}
+
+	t, ok := asTimestamp(v)
+	if !ok {
+		return nil, errors.New("function 'extract' expects a field and a timestamp, date or time")
+	}
+
+	switch field {
+	case "year":
+		return int64(t.Year()), nil
+	case "quarter":
+		return int64(t.Month()-1)/3 + 1, nil
+	case "month":
+		return int64(t.Month()), nil
+	case "week":
+		_, week := t.ISOWeek()
+		return int64(week), nil
+	case "day":
+		return int64(t.Day()), nil
+	case "dow":
+		return int64(t.Weekday()), nil
+	case "doy":
+		return int64(t.YearDay()), nil
+	case "hour":
+		return int64(t.Hour()), nil
+	case "minute":
+		return int64(t.Minute()), nil
+	case "second":
+		return float64(t.Second()) + float64(t.Nanosecond())/1e9, nil
+	case "epoch":
+		return float64(t.UnixMicro()) / 1e6, nil
+	}
+
+	return nil, fmt.Errorf("field '%s' is not supported by extract", field)
+}
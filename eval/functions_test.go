@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFunctions(t *testing.T) {
+	ts := time.Date(2024, time.May, 16, 13, 45, 30, 500000000, time.UTC)
+
+	tests := []struct {
+		name    string
+		args    []any
+		want    any
+		wantErr string
+	}{
+		{
+			name: "date_trunc",
+			args: []any{"hour", ts},
+			want: time.Date(2024, time.May, 16, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "date_trunc",
+			args: []any{"WEEK", ts},
+			want: time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "date_trunc",
+			args: []any{"quarter", NewDate(2024, time.May, 16)},
+			want: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "date_trunc",
+			args:    []any{"decade", ts},
+			wantErr: "unit 'decade' is not supported by date_trunc",
+		},
+		{
+			name: "extract",
+			args: []any{"year", ts},
+			want: int64(2024),
+		},
+		{
+			name: "extract",
+			args: []any{"dow", NewDate(2024, time.May, 16)},
+			want: int64(4),
+		},
+		{
+			name: "extract",
+			args: []any{"second", ts},
+			want: 30.5,
+		},
+		{
+			name: "extract",
+			args: []any{"minute", TimeOfDay(13*time.Hour + 45*time.Minute)},
+			want: int64(45),
+		},
+		{
+			name:    "extract",
+			args:    []any{"year", TimeOfDay(0)},
+			wantErr: "field 'year' can't be extracted from a time",
+		},
+		{
+			name:    "extract",
+			args:    []any{"year"},
+			wantErr: "function 'extract' expects a field and a timestamp, date or time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := functions[tt.name].call(tt.args)
+			if err != nil {
+				if err.Error() != tt.wantErr {
+					t.Errorf("unexpected error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if tt.wantErr != "" {
+				t.Errorf("expected error %v", tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// JSON is a document kept as compact text.
+type JSON string
+
+// ParseJSON validates the document and removes its insignificant whitespace.
+func ParseJSON(s string) (JSON, error) {
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, []byte(s)); err != nil {
+		return "", fmt.Errorf("invalid json: %w", err)
+	}
+
+	return JSON(buf.String()), nil
+}
+
+func (j JSON) String() string {
+	return string(j)
+}
+
+// MarshalJSON writes the document as it is, instead of as a string.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	return []byte(j), nil
+}
+
+// Type returns the type of the document's top level value,
+// which is one of object, array, string, number, boolean or null.
+func (j JSON) Type() string {
+	if len(j) == 0 {
+		return "null"
+	}
+
+	switch j[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+
+	return "number"
+}
+
+// Get returns the value of the key in an object, or the element at the
+// index of an array, negative indexes count from the end of the array.
+// It reports false when the document has no such key or element.
+func (j JSON) Get(path any) (JSON, bool, error) {
+	switch p := path.(type) {
+	case string:
+		if j.Type() != "object" {
+			return "", false, nil
+		}
+
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(j), &obj); err != nil {
+			return "", false, err
+		}
+
+		v, ok := obj[p]
+		return JSON(v), ok, nil
+	case int64:
+		if j.Type() != "array" {
+			return "", false, nil
+		}
+
+		var arr []json.RawMessage
+		if err := json.Unmarshal([]byte(j), &arr); err != nil {
+			return "", false, err
+		}
+
+		if p < 0 {
+			p += int64(len(arr))
+		}
+
+		if p < 0 || p >= int64(len(arr)) {
+			return "", false, nil
+		}
+
+		return JSON(arr[p]), true, nil
+	}
+
+	return "", false, fmt.Errorf("json path element must be a string or an integer, but got '%s'", genericValueType(path))
+}
+
+// Text returns strings without their quotes and any
+// other value as its json text, null results in nothing.
+func (j JSON) Text() (any, error) {
+	switch j.Type() {
+	case "null":
+		return nil, nil
+	case "string":
+		var s string
+		if err := json.Unmarshal([]byte(j), &s); err != nil {
+			return nil, err
+		}
+
+		return s, nil
+	}
+
+	return string(j), nil
+}
+
+// jsonExtract follows the path through the document, when any of its
+// elements doesn't exist the result is nothing. With asText, the value
+// found is given as text instead of as a json document. Nothing has no
+// paths either, so a chain of paths gives nothing once one is missing.
+func jsonExtract(doc any, path []any, asText bool) (any, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	j, ok := doc.(JSON)
+	if !ok {
+		return nil, fmt.Errorf("json path can't be applied to '%s'", genericValueType(doc))
+	}
+
+	for _, p := range path {
+		v, found, err := j.Get(p)
+		if err != nil || !found {
+			return nil, err
+		}
+
+		j = v
+	}
+
+	if asText {
+		return j.Text()
+	}
+
+	return j, nil
+}
+
+func jsonArrayLength(doc any) (any, error) {
+	j, ok := doc.(JSON)
+	if !ok || j.Type() != "array" {
+		return nil, errors.New("function 'json_array_length' expects a json array")
+	}
+
+	var arr []json.RawMessage
+	if err := json.Unmarshal([]byte(j), &arr); err != nil {
+		return nil, err
+	}
+
+	return int64(len(arr)), nil
+}
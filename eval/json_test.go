@@ -0,0 +1,93 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONExtract(t *testing.T) {
+	doc := JSON(`{"type":"click","n":1.5,"tags":["a","b",null],"meta":{"ok":true}}`)
+
+	tests := []struct {
+		path    []any
+		asText  bool
+		want    any
+		wantErr string
+	}{
+		{path: []any{"type"}, want: JSON(`"click"`)},
+		{path: []any{"type"}, asText: true, want: "click"},
+		{path: []any{"n"}, asText: true, want: "1.5"},
+		{path: []any{"tags", int64(1)}, asText: true, want: "b"},
+		{path: []any{"tags", int64(-1)}, want: JSON(`null`)},
+		{path: []any{"tags", int64(-1)}, asText: true, want: nil},
+		{path: []any{"meta"}, asText: true, want: `{"ok":true}`},
+		{path: []any{"missing", "key"}, want: nil},
+		{path: []any{"tags", int64(3)}, want: nil},
+		{path: []any{int64(0)}, want: nil},
+		{path: []any{true}, wantErr: "json path element must be a string or an integer, but got 'bool'"},
+	}
+
+	for i, tt := range tests {
+		got, err := jsonExtract(doc, tt.path, tt.asText)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("#%d expected error '%s', but got %v", i, tt.wantErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("#%d %s", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d expected %#v, but got %#v", i, tt.want, got)
+		}
+	}
+}
+
+func TestJSONExtractChained(t *testing.T) {
+	docs := []JSON{`{"x":{"y":[1,2,3]}}`, `{"x":1}`, `{}`}
+	want := []any{"1", nil, nil}
+
+	for i, doc := range docs {
+		var got any = doc
+		var err error
+		for _, p := range []any{"x", "y"} {
+			got, err = jsonExtract(got, []any{p}, false)
+			if err != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			got, err = jsonExtract(got, []any{int64(0)}, true)
+		}
+
+		if err != nil {
+			t.Errorf("#%d %s", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("#%d expected %#v, but got %#v", i, want[i], got)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	got, err := ParseJSON(" [ 1, {\"a\" : \"b c\"} ]\n")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if want := JSON(`[1,{"a":"b c"}]`); got != want || got.Type() != "array" {
+		t.Errorf("expected %s, but got %s of type %s", want, got, got.Type())
+	}
+
+	if _, err := ParseJSON(`{"a":}`); err == nil {
+		t.Error("expected an invalid document to be rejected")
+	}
+}
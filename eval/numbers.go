@@ -0,0 +1,154 @@
+package eval
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// compareNumbers compares integers, decimals and floats without losing
+// precision, it reports false when either side isn't a number.
+func compareNumbers(left, right any) (int, bool) {
+	if c, ok := compareDecimal(left, right); ok {
+		return c, true
+	}
+
+	switch l := left.(type) {
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			return cmp.Compare(l, r), true
+		case float64:
+			return compareIntFloat(l, r), true
+		}
+	case float64:
+		switch r := right.(type) {
+		case int64:
+			return -compareIntFloat(r, l), true
+		case float64:
+			return cmp.Compare(l, r), true
+		}
+	}
+
+	return 0, false
+}
+
+// compareIntFloat can't just convert i to a float64, integers with more than
+// 53 significant bits would be rounded and compare equal to their neighbours.
+func compareIntFloat(i int64, f float64) int {
+	switch {
+	case math.IsNaN(f):
+		// same as cmp.Compare, NaN is less than any number
+		return 1
+	case f >= math.MaxInt64:
+		return -1
+	case f < math.MinInt64:
+		return 1
+	}
+
+	t := math.Trunc(f)
+	if c := cmp.Compare(i, int64(t)); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(t, f)
+}
+
+// numberArithmetic applies an arithmetic operator to two numbers. Integers
+// stay exact unless they are mixed with floats, decimals are only turned into
+// floats when mixed with them. It reports false when either side isn't a number.
+func numberArithmetic(operator OperatorType, left, right any) (any, bool, error) {
+	if v, ok, err := decimalArithmetic(operator, left, right); ok {
+		return v, true, err
+	}
+
+	if l, ok := left.(int64); ok {
+		if r, ok := right.(int64); ok {
+			v, err := integerArithmetic(operator, l, r)
+			return v, true, err
+		}
+	}
+
+	l, lok := toFloat(left)
+	r, rok := toFloat(right)
+	if !lok || !rok {
+		return nil, false, nil
+	}
+
+	switch operator {
+	case Add:
+		return l + r, true, nil
+	case Subtract:
+		return l - r, true, nil
+	case Multiply:
+		return l * r, true, nil
+	case Divide:
+		if r == 0 {
+			return nil, true, errDivisionByZero
+		}
+
+		return l / r, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// integerArithmetic fails instead of overflowing, divisions
+// are truncated towards zero.
+func integerArithmetic(operator OperatorType, l, r int64) (int64, error) {
+	switch operator {
+	case Add:
+		s := l + r
+		if (s > l) != (r > 0) {
+			return 0, errIntegerOutOfRange
+		}
+
+		return s, nil
+	case Subtract:
+		s := l - r
+		if (s > l) != (r < 0) {
+			return 0, errIntegerOutOfRange
+		}
+
+		return s, nil
+	case Multiply:
+		if l == 0 || r == 0 {
+			return 0, nil
+		}
+
+		p := l * r
+		if p/r != l || (l == -1 && r == math.MinInt64) || (r == -1 && l == math.MinInt64) {
+			return 0, errIntegerOutOfRange
+		}
+
+		return p, nil
+	case Divide:
+		if r == 0 {
+			return 0, errDivisionByZero
+		}
+
+		if l == math.MinInt64 && r == -1 {
+			return 0, errIntegerOutOfRange
+		}
+
+		return l / r, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator '%s'", operator)
+}
+
+var errIntegerOutOfRange = errors.New("integer out of range")
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case Decimal:
+		return n.Float(), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,227 @@
+package eval
+
+import "slices"
+
+// mirrored holds the operator that keeps a comparison
+// equivalent once its operands are swapped.
+var mirrored = map[OperatorType]OperatorType{
+	Equal:            Equal,
+	NotEqual:         NotEqual,
+	GreaterThan:      LessThan,
+	GreaterEqualThan: LessEqualThan,
+	LessThan:         GreaterThan,
+	LessEqualThan:    GreaterEqualThan,
+}
+
+// IsLiteral tells if the expression is a constant value.
+func (expr *Expression) IsLiteral() bool {
+	return expr.Type == Operand && expr.Identifier == ""
+}
+
+// Identifiers gives the identifiers the expression reads,
+// in the order they first appear in it.
+func Identifiers(expr *Expression) []string {
+	var names []string
+
+	var walk func(*Expression)
+	walk = func(e *Expression) {
+		if e == nil {
+			return
+		}
+
+		if e.Identifier != "" && !slices.Contains(names, e.Identifier) {
+			names = append(names, e.Identifier)
+		}
+
+		walk(e.Left)
+		walk(e.Right)
+		walk(e.Subject)
+		for _, b := range e.Branches {
+			walk(b.When)
+			walk(b.Then)
+		}
+		walk(e.Else)
+		for _, a := range e.Args {
+			walk(a)
+		}
+	}
+	walk(expr)
+
+	return names
+}
+
+// Optimize returns an expression equivalent to expr where constant subtrees
+// are folded, branches that are always true or always false are removed and
+// comparisons are normalised to have the literal on their right side, the
+// given expression is not modified. Subtrees that fail to be folded are kept
+// as they are, so the error is reported when they are evaluated.
+func Optimize(expr *Expression) *Expression {
+	if expr == nil {
+		return nil
+	}
+
+	switch expr.Type {
+	case Operator:
+		return optimizeOperator(expr)
+	case Case:
+		return optimizeCase(expr)
+	case Function:
+		return optimizeFunction(expr)
+	}
+
+	return expr
+}
+
+func optimizeFunction(expr *Expression) *Expression {
+	e := *expr
+	e.Args = make([]*Expression, len(expr.Args))
+
+	constant := true
+	for i, a := range expr.Args {
+		e.Args[i] = Optimize(a)
+		constant = constant && e.Args[i].IsLiteral()
+	}
+
+	f, ok := functions[e.Function]
+	if !ok || f.volatile || !constant {
+		return &e
+	}
+
+	args := make([]any, len(e.Args))
+	for i, a := range e.Args {
+		args[i] = a.GoValue
+	}
+
+	v, err := f.call(args)
+	if err != nil {
+		return &e
+	}
+
+	return literal(&e, v)
+}
+
+func optimizeOperator(expr *Expression) *Expression {
+	e := *expr
+	e.Left = Optimize(expr.Left)
+	e.Right = Optimize(expr.Right)
+
+	switch e.Operator {
+	case And:
+		if isBoolLiteral(e.Left, false) || isBoolLiteral(e.Right, false) {
+			return literal(&e, false)
+		}
+
+		if isBoolLiteral(e.Left, true) {
+			return e.Right
+		}
+
+		if isBoolLiteral(e.Right, true) {
+			return e.Left
+		}
+
+		return &e
+	case Or:
+		if isBoolLiteral(e.Left, true) || isBoolLiteral(e.Right, true) {
+			return literal(&e, true)
+		}
+
+		if isBoolLiteral(e.Left, false) {
+			return e.Right
+		}
+
+		if isBoolLiteral(e.Right, false) {
+			return e.Left
+		}
+
+		return &e
+	}
+
+	if e.Left.IsLiteral() && e.Right.IsLiteral() {
+		if op, ok := binaryOperators[e.Operator]; ok {
+			if v, err := op(e.Left.GoValue, e.Right.GoValue); err == nil {
+				return literal(&e, v)
+			}
+		}
+
+		return &e
+	}
+
+	if m, ok := mirrored[e.Operator]; ok && e.Left.IsLiteral() {
+		e.Operator = m
+		e.Left, e.Right = e.Right, e.Left
+	}
+
+	return &e
+}
+
+func optimizeCase(expr *Expression) *Expression {
+	e := *expr
+	e.Subject = Optimize(expr.Subject)
+	e.Else = Optimize(expr.Else)
+	e.Branches = make([]*CaseBranch, 0, len(expr.Branches))
+
+	for _, b := range expr.Branches {
+		branch := &CaseBranch{
+			When: Optimize(b.When),
+			Then: Optimize(b.Then),
+		}
+
+		// whether the branch is taken can only be known if the
+		// condition doesn't depend on the row being evaluated
+		if !branch.When.IsLiteral() || (e.Subject != nil && !e.Subject.IsLiteral()) {
+			e.Branches = append(e.Branches, branch)
+			continue
+		}
+
+		matched, err := caseMatches(e.Subject != nil, subjectValue(&e), branch.When.GoValue)
+		if err != nil {
+			e.Branches = append(e.Branches, branch)
+			continue
+		}
+
+		if !matched {
+			continue
+		}
+
+		// every branch before this one was kept because it can't
+		// be decided, so this one becomes the new fallback
+		if len(e.Branches) == 0 {
+			return branch.Then
+		}
+
+		e.Else = branch.Then
+		break
+	}
+
+	if len(e.Branches) == 0 {
+		if e.Else != nil {
+			return e.Else
+		}
+
+		return literal(&e, nil)
+	}
+
+	return &e
+}
+
+func subjectValue(expr *Expression) any {
+	if expr.Subject == nil {
+		return nil
+	}
+
+	return expr.Subject.GoValue
+}
+
+func isBoolLiteral(expr *Expression, v bool) bool {
+	return expr.IsLiteral() && expr.GoValue == v
+}
+
+func literal(at *Expression, v any) *Expression {
+	return &Expression{
+		Type:    Operand,
+		GoValue: v,
+
+		Line:   at.Line,
+		Column: at.Column,
+	}
+}
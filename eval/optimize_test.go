@@ -0,0 +1,205 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func lit(v any) *Expression {
+	return &Expression{Type: Operand, GoValue: v}
+}
+
+func ident(name string) *Expression {
+	return &Expression{Type: Operand, Identifier: name}
+}
+
+func op(operator OperatorType, left, right *Expression) *Expression {
+	return &Expression{Type: Operator, Operator: operator, Left: left, Right: right}
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name string
+		expr *Expression
+		want *Expression
+	}{
+		{
+			name: "nil expression",
+		},
+		{
+			name: "folds constant comparison",
+			expr: op(And, op(GreaterThan, lit(float64(3)), lit(float64(2))), op(Equal, ident("foo"), lit(float64(23)))),
+			want: op(Equal, ident("foo"), lit(float64(23))),
+		},
+		{
+			name: "and with false is always false",
+			expr: op(And, op(Equal, ident("foo"), lit(float64(23))), op(LessThan, lit(float64(3)), lit(float64(2)))),
+			want: lit(false),
+		},
+		{
+			name: "or with true is always true",
+			expr: op(Or, op(Equal, lit("a"), lit("a")), ident("foo")),
+			want: lit(true),
+		},
+		{
+			name: "or with false keeps the other side",
+			expr: op(Or, ident("foo"), lit(false)),
+			want: ident("foo"),
+		},
+		{
+			name: "normalises literal to the right side",
+			expr: op(LessThan, lit(float64(3)), ident("foo")),
+			want: op(GreaterThan, ident("foo"), lit(float64(3))),
+		},
+		{
+			name: "normalises equality",
+			expr: op(NotEqual, lit("a"), ident("foo")),
+			want: op(NotEqual, ident("foo"), lit("a")),
+		},
+		{
+			name: "keeps subtrees that fail to be folded",
+			expr: op(And, ident("foo"), op(LessThan, lit("a"), lit(float64(1)))),
+			want: op(And, ident("foo"), op(LessThan, lit("a"), lit(float64(1)))),
+		},
+		{
+			name: "removes branches that are never taken",
+			expr: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{When: op(Equal, lit(float64(1)), lit(float64(2))), Then: lit("a")},
+					{When: ident("foo"), Then: lit("b")},
+				},
+				Else: lit("c"),
+			},
+			want: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{When: ident("foo"), Then: lit("b")},
+				},
+				Else: lit("c"),
+			},
+		},
+		{
+			name: "branch always taken becomes the result",
+			expr: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{When: lit(false), Then: lit("a")},
+					{When: op(GreaterEqualThan, lit(float64(2)), lit(float64(2))), Then: lit("b")},
+					{When: ident("foo"), Then: lit("c")},
+				},
+			},
+			want: lit("b"),
+		},
+		{
+			name: "branch always taken becomes the fallback",
+			expr: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{When: ident("foo"), Then: lit("a")},
+					{When: lit(true), Then: lit("b")},
+					{When: ident("bar"), Then: lit("c")},
+				},
+				Else: lit("d"),
+			},
+			want: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{When: ident("foo"), Then: lit("a")},
+				},
+				Else: lit("b"),
+			},
+		},
+		{
+			name: "simple case with constant subject",
+			expr: &Expression{
+				Type:    Case,
+				Subject: lit("x"),
+				Branches: []*CaseBranch{
+					{When: lit("y"), Then: lit(float64(1))},
+					{When: lit("x"), Then: lit(float64(2))},
+				},
+			},
+			want: lit(float64(2)),
+		},
+		{
+			name: "folds functions of constants",
+			expr: &Expression{Type: Function, Function: "round", Args: []*Expression{lit(NewDecimal(125, 2)), lit(int64(1))}},
+			want: lit(NewDecimal(13, 1)),
+		},
+		{
+			name: "volatile functions are never folded",
+			expr: op(Equal, &Expression{Type: Function, Function: "uuidv7", Args: []*Expression{}}, ident("foo")),
+			want: op(Equal, &Expression{Type: Function, Function: "uuidv7", Args: []*Expression{}}, ident("foo")),
+		},
+		{
+			name: "case without any branch left",
+			expr: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{When: lit(false), Then: lit("a")},
+				},
+			},
+			want: lit(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Optimize(tt.expr)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Optimize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptimizeDoesNotModifyExpression(t *testing.T) {
+	expr := op(And, lit(true), op(LessThan, lit(float64(3)), ident("foo")))
+	want := op(And, lit(true), op(LessThan, lit(float64(3)), ident("foo")))
+
+	Optimize(expr)
+
+	if !reflect.DeepEqual(expr, want) {
+		t.Errorf("Optimize() modified the expression: %+v", expr)
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	tests := []struct {
+		name string
+		expr *Expression
+		want []string
+	}{
+		{
+			name: "nil expression",
+		},
+		{
+			name: "literal",
+			expr: lit(int64(1)),
+		},
+		{
+			name: "operators",
+			expr: op(And, op(Equal, ident("foo"), lit(int64(1))), op(GreaterThan, ident("bar"), ident("foo"))),
+			want: []string{"foo", "bar"},
+		},
+		{
+			name: "case and function",
+			expr: &Expression{
+				Type:     Case,
+				Subject:  ident("foo"),
+				Branches: []*CaseBranch{{When: lit(int64(1)), Then: ident("bar")}},
+				Else:     &Expression{Type: Function, Function: "lower", Args: []*Expression{ident("baz")}},
+			},
+			want: []string{"foo", "bar", "baz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Identifiers(tt.expr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Identifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package eval
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04:05.999999"
+	day        = 24 * time.Hour
+)
+
+// Date is a calendar day, kept as a time.Time at midnight UTC.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateFromDays returns the date that is the given amount of days after 1970-01-01.
+func DateFromDays(days int64) Date {
+	return Date{time.Unix(days*int64(day/time.Second), 0).UTC()}
+}
+
+// Days returns the amount of days since 1970-01-01.
+func (d Date) Days() int64 {
+	return d.Unix() / int64(day/time.Second)
+}
+
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// TimeOfDay is a time without a date, kept as the time elapsed since midnight.
+type TimeOfDay time.Duration
+
+func (t TimeOfDay) String() string {
+	return time.Time{}.Add(time.Duration(t)).Format(timeLayout)
+}
+
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// Interval is an amount of time, months and days are kept apart
+// from the rest because their duration depends on when they're applied.
+type Interval struct {
+	Months   int64
+	Days     int64
+	Duration time.Duration
+}
+
+func (i Interval) String() string {
+	parts := []string{}
+	if i.Months != 0 {
+		parts = append(parts, fmt.Sprintf("%d months", i.Months))
+	}
+	if i.Days != 0 {
+		parts = append(parts, fmt.Sprintf("%d days", i.Days))
+	}
+	if i.Duration != 0 || len(parts) == 0 {
+		parts = append(parts, i.Duration.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (i Interval) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+func (i Interval) negate() Interval {
+	return Interval{Months: -i.Months, Days: -i.Days, Duration: -i.Duration}
+}
+
+// approximate is only used for ordering, a month counts as
+// 30 days and a day as 24 hours.
+func (i Interval) approximate() time.Duration {
+	return time.Duration(i.Months*30+i.Days)*day + i.Duration
+}
+
+// compare orders intervals by their approximate length, intervals are only
+// equal when their months, days and the rest are, so ones of the same length
+// that differ, such as 1 month and 30 days, are ordered by those in turn.
+func (i Interval) compare(o Interval) int {
+	for _, c := range []int{
+		cmp.Compare(i.approximate(), o.approximate()),
+		cmp.Compare(i.Months, o.Months),
+		cmp.Compare(i.Days, o.Days),
+		cmp.Compare(i.Duration, o.Duration),
+	} {
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func (i Interval) addTo(t time.Time) time.Time {
+	return t.AddDate(0, int(i.Months), int(i.Days)).Add(i.Duration)
+}
+
+// ParseDate parses dates in the ISO-8601 format, such as 2024-01-31.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date '%s', expected format YYYY-MM-DD", s)
+	}
+
+	return Date{t}, nil
+}
+
+// ParseTime parses times in the ISO-8601 format, such as 13:45, 13:45:30 or 13:45:30.123.
+func ParseTime(s string) (TimeOfDay, error) {
+	for _, layout := range []string{"15:04", "15:04:05", timeLayout} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return TimeOfDay(t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)).Truncate(time.Microsecond)), nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid time '%s', expected format HH:MM[:SS[.ffffff]]", s)
+}
+
+// ParseTimestamp parses timestamps in the ISO-8601 format, timestamps without
+// an offset are taken as UTC. The date and time can be separated by a space.
+func ParseTimestamp(s string) (time.Time, error) {
+	s = strings.Replace(s, " ", "T", 1)
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04", dateLayout} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t.UTC().Truncate(time.Microsecond), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp '%s', expected format YYYY-MM-DDTHH:MM:SS[.ffffff][Z|±HH:MM]", s)
+}
+
+var intervalUnits = map[string]Interval{
+	"microsecond": {Duration: time.Microsecond},
+	"millisecond": {Duration: time.Millisecond},
+	"second":      {Duration: time.Second},
+	"minute":      {Duration: time.Minute},
+	"hour":        {Duration: time.Hour},
+	"day":         {Days: 1},
+	"week":        {Days: 7},
+	"month":       {Months: 1},
+	"year":        {Months: 12},
+}
+
+// ParseInterval parses a sequence of quantities followed by their unit,
+// such as "1 day", "-2 hours 30 minutes" or "1 year 6 months".
+func ParseInterval(s string) (Interval, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return Interval{}, fmt.Errorf("invalid interval '%s', expected quantities followed by their unit", s)
+	}
+
+	var i Interval
+	for f := 0; f < len(fields); f += 2 {
+		n, err := strconv.ParseInt(fields[f], 10, 64)
+		if err != nil {
+			return Interval{}, fmt.Errorf("invalid quantity '%s' in interval '%s'", fields[f], s)
+		}
+
+		unit, ok := intervalUnits[strings.TrimSuffix(fields[f+1], "s")]
+		if !ok {
+			return Interval{}, fmt.Errorf("invalid unit '%s' in interval '%s'", fields[f+1], s)
+		}
+
+		i.Months += n * unit.Months
+		i.Days += n * unit.Days
+		i.Duration += time.Duration(n) * unit.Duration
+	}
+
+	return i, nil
+}
+
+// compareTemporal orders dates, times, timestamps and intervals, dates can
+// be compared with timestamps as if they were at midnight. It reports false
+// when the values can't be compared with each other.
+func compareTemporal(left, right any) (int, bool) {
+	if l, ok := asTimestamp(left); ok {
+		if r, ok := asTimestamp(right); ok {
+			return l.Compare(r), true
+		}
+	}
+
+	switch l := left.(type) {
+	case TimeOfDay:
+		if r, ok := right.(TimeOfDay); ok {
+			return cmp.Compare(l, r), true
+		}
+	case Interval:
+		if r, ok := right.(Interval); ok {
+			return l.compare(r), true
+		}
+	}
+
+	return 0, false
+}
+
+func asTimestamp(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case Date:
+		return t.Time, true
+	}
+
+	return time.Time{}, false
+}
+
+// wholeDays gives the number as a count of days, dates are only shifted
+// by whole ones, it reports false for fractional numbers and non-numbers.
+func wholeDays(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case float64:
+		if n != math.Trunc(n) || n >= math.MaxInt64 || n < math.MinInt64 {
+			return 0, false
+		}
+
+		return int64(n), true
+	case Decimal:
+		if d, err := n.Round(0); err == nil && d.Equal(n) {
+			return d.unscaled, true
+		}
+	}
+
+	return 0, false
+}
+
+// addTemporal handles additions and subtractions involving dates and times,
+// it reports false when the operation isn't defined for those values.
+func addTemporal(left, right any, subtract bool) (any, bool) {
+	// days can be given as any whole number
+	if _, ok := left.(Date); ok {
+		if n, ok := wholeDays(right); ok {
+			right = n
+		}
+	} else if _, ok := right.(Date); ok && !subtract {
+		if n, ok := wholeDays(left); ok {
+			left = n
+		}
+	}
+
+	if subtract {
+		if l, ok := left.(Date); ok {
+			if r, ok := right.(Date); ok {
+				return l.Days() - r.Days(), true
+			}
+		}
+
+		if l, ok := asTimestamp(left); ok {
+			if r, ok := asTimestamp(right); ok {
+				return Interval{Duration: l.Sub(r)}, true
+			}
+		}
+
+		if l, ok := left.(TimeOfDay); ok {
+			if r, ok := right.(TimeOfDay); ok {
+				return Interval{Duration: time.Duration(l - r)}, true
+			}
+		}
+
+		switch r := right.(type) {
+		case Interval:
+			right = r.negate()
+		case int64:
+			right = -r
+		}
+	} else if _, ok := right.(Interval); !ok {
+		// addition is commutative, so intervals and days
+		// are always handled as the right operand
+		if _, ok := left.(Interval); ok {
+			left, right = right, left
+		} else if _, ok := left.(int64); ok {
+			left, right = right, left
+		}
+	}
+
+	switch l := left.(type) {
+	case Date:
+		switch r := right.(type) {
+		case int64:
+			return DateFromDays(l.Days() + r), true
+		case Interval:
+			return r.addTo(l.Time), true
+		}
+	case time.Time:
+		if r, ok := right.(Interval); ok {
+			return r.addTo(l), true
+		}
+	case TimeOfDay:
+		if r, ok := right.(Interval); ok {
+			t := (time.Duration(l) + r.Duration) % day
+			if t < 0 {
+				t += day
+			}
+			return TimeOfDay(t), true
+		}
+	case Interval:
+		if r, ok := right.(Interval); ok {
+			return Interval{Months: l.Months + r.Months, Days: l.Days + r.Days, Duration: l.Duration + r.Duration}, true
+		}
+	}
+
+	return nil, false
+}
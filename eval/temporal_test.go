@@ -0,0 +1,195 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTemporal(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (any, error)
+		input   string
+		want    any
+		wantErr string
+	}{
+		{
+			name:  "date",
+			parse: func(s string) (any, error) { return ParseDate(s) },
+			input: "2024-02-29",
+			want:  NewDate(2024, time.February, 29),
+		},
+		{
+			name:    "date that doesn't exist",
+			parse:   func(s string) (any, error) { return ParseDate(s) },
+			input:   "2023-02-29",
+			wantErr: "invalid date '2023-02-29', expected format YYYY-MM-DD",
+		},
+		{
+			name:  "time without seconds",
+			parse: func(s string) (any, error) { return ParseTime(s) },
+			input: "13:45",
+			want:  TimeOfDay(13*time.Hour + 45*time.Minute),
+		},
+		{
+			name:  "time with fraction",
+			parse: func(s string) (any, error) { return ParseTime(s) },
+			input: "00:00:01.5",
+			want:  TimeOfDay(1500 * time.Millisecond),
+		},
+		{
+			name:  "timestamp without offset is utc",
+			parse: func(s string) (any, error) { return ParseTimestamp(s) },
+			input: "2024-01-31 10:00:00",
+			want:  time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "timestamp with offset",
+			parse: func(s string) (any, error) { return ParseTimestamp(s) },
+			input: "2024-01-31T10:00:00.123456789-03:00",
+			want:  time.Date(2024, time.January, 31, 13, 0, 0, 123456000, time.UTC),
+		},
+		{
+			name:  "interval",
+			parse: func(s string) (any, error) { return ParseInterval(s) },
+			input: "1 year 2 months -3 days 4 hours",
+			want:  Interval{Months: 14, Days: -3, Duration: 4 * time.Hour},
+		},
+		{
+			name:    "interval without unit",
+			parse:   func(s string) (any, error) { return ParseInterval(s) },
+			input:   "1 day 2",
+			wantErr: "invalid interval '1 day 2', expected quantities followed by their unit",
+		},
+		{
+			name:    "interval with unknown unit",
+			parse:   func(s string) (any, error) { return ParseInterval(s) },
+			input:   "2 fortnights",
+			wantErr: "invalid unit 'fortnights' in interval '2 fortnights'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.input)
+			if err != nil {
+				if err.Error() != tt.wantErr {
+					t.Errorf("unexpected error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if tt.wantErr != "" {
+				t.Errorf("expected error %v", tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTemporal(t *testing.T) {
+	jan31 := NewDate(2024, time.January, 31)
+	noon := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		left     any
+		right    any
+		subtract bool
+		want     any
+	}{
+		{
+			name:  "date plus days",
+			left:  jan31,
+			right: int64(1),
+			want:  NewDate(2024, time.February, 1),
+		},
+		{
+			name:  "days plus date",
+			left:  int64(-31),
+			right: jan31,
+			want:  NewDate(2023, time.December, 31),
+		},
+		{
+			name:     "date minus whole decimal",
+			left:     jan31,
+			right:    NewDecimal(200, 2),
+			subtract: true,
+			want:     NewDate(2024, time.January, 29),
+		},
+		{
+			name:  "date plus whole float",
+			left:  jan31,
+			right: 1.0,
+			want:  NewDate(2024, time.February, 1),
+		},
+		{
+			name:  "date plus fractional days",
+			left:  jan31,
+			right: 1.5,
+		},
+		{
+			name:     "date minus date",
+			left:     NewDate(2024, time.March, 1),
+			right:    jan31,
+			subtract: true,
+			want:     int64(30),
+		},
+		{
+			name:  "date plus interval is a timestamp",
+			left:  jan31,
+			right: Interval{Days: 1, Duration: time.Hour},
+			want:  time.Date(2024, time.February, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timestamp minus month",
+			left:     noon,
+			right:    Interval{Months: 1},
+			subtract: true,
+			want:     time.Date(2023, time.December, 31, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timestamp minus date",
+			left:     noon,
+			right:    jan31,
+			subtract: true,
+			want:     Interval{Duration: 12 * time.Hour},
+		},
+		{
+			name:  "time wraps around midnight",
+			left:  TimeOfDay(23 * time.Hour),
+			right: Interval{Duration: 2 * time.Hour},
+			want:  TimeOfDay(time.Hour),
+		},
+		{
+			name:  "interval plus interval",
+			left:  Interval{Months: 1},
+			right: Interval{Days: 2},
+			want:  Interval{Months: 1, Days: 2},
+		},
+		{
+			name:  "timestamp plus timestamp",
+			left:  noon,
+			right: noon,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := addTemporal(tt.left, tt.right, tt.subtract)
+			if ok != (tt.want != nil) {
+				t.Errorf("addTemporal() ok = %v, want %v", ok, tt.want != nil)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addTemporal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
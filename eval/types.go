@@ -0,0 +1,296 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ValueType string
+
+const (
+	NumberValue ValueType = "number"
+	StringValue ValueType = "string"
+	BoolValue   ValueType = "bool"
+	BytesValue  ValueType = "bytes"
+	UUIDValue   ValueType = "uuid"
+	JSONValue   ValueType = "json"
+
+	// values of every enumerated type, they can be
+	// compared with strings holding their labels
+	EnumValue ValueType = "enum"
+
+	DateValue      ValueType = "date"
+	TimeValue      ValueType = "time"
+	TimestampValue ValueType = "timestamp"
+	IntervalValue  ValueType = "interval"
+)
+
+// InferType checks that every node of the expression tree is applied to
+// values of the right type and returns the type the expression results in,
+// types holds the type of each identifier the expression may refer to.
+func InferType(expr *Expression, types map[string]ValueType) (ValueType, error) {
+	if expr == nil {
+		return "", errors.New("expected expression, but got nothing")
+	}
+
+	switch expr.Type {
+	case Operand:
+		if expr.Identifier != "" {
+			t, ok := types[expr.Identifier]
+			if !ok {
+				return "", fmt.Errorf("column '%s' does not exist at %d:%d", expr.Identifier, expr.Line, expr.Column)
+			}
+
+			return t, nil
+		}
+
+		t := genericValueType(expr.GoValue)
+		if t == "" {
+			return "", fmt.Errorf("unsupported literal '%v' at %d:%d", expr.GoValue, expr.Line, expr.Column)
+		}
+
+		return t, nil
+	case Operator:
+		return expr.inferOperatorType(types)
+	case Case:
+		return expr.inferCaseType(types)
+	case Function:
+		return expr.inferFunctionType(types)
+	}
+
+	return "", fmt.Errorf("unknown expression type at %d:%d", expr.Line, expr.Column)
+}
+
+func (expr *Expression) inferOperatorType(types map[string]ValueType) (ValueType, error) {
+	if expr.Left == nil || expr.Right == nil {
+		return "", fmt.Errorf("operator '%s' is missing an operand at %d:%d", expr.Operator, expr.Line, expr.Column)
+	}
+
+	left, err := InferType(expr.Left, types)
+	if err != nil {
+		return "", err
+	}
+
+	right, err := InferType(expr.Right, types)
+	if err != nil {
+		return "", err
+	}
+
+	switch expr.Operator {
+	case And, Or:
+		if left != BoolValue || right != BoolValue {
+			return "", fmt.Errorf("both sides of a logical operation must be boolean values, but got '%s' and '%s' at %d:%d", left, right, expr.Line, expr.Column)
+		}
+	case Equal, NotEqual, GreaterThan, GreaterEqualThan, LessThan, LessEqualThan:
+		// comparisons with ANY(array) are made against its elements
+		l, lany := quantifiedType(left)
+		r, rany := quantifiedType(right)
+		if lany && rany {
+			return "", fmt.Errorf("ANY can only be applied to one side of a comparison at %d:%d", expr.Line, expr.Column)
+		}
+		left, right = l, r
+
+		if left == JSONValue || right == JSONValue {
+			return "", fmt.Errorf("json documents can't be compared, extract a value with '->>' first at %d:%d", expr.Line, expr.Column)
+		}
+
+		if !canCompare(left, right) {
+			return "", fmt.Errorf("both sides of a comparison operation must be of the same type, but got '%s' and '%s' at %d:%d", left, right, expr.Line, expr.Column)
+		}
+	case Add, Subtract, Multiply, Divide:
+		t, ok := arithmeticType(expr.Operator, left, right)
+		if !ok {
+			return "", fmt.Errorf("operator '%s' can't be applied to '%s' and '%s' at %d:%d", expr.Operator, left, right, expr.Line, expr.Column)
+		}
+
+		// columns may hold fractional numbers too, but those
+		// can only be told apart ahead of time for literals
+		for _, days := range []*Expression{expr.Left, expr.Right} {
+			if t == DateValue && days.Type == Operand && days.Identifier == "" && genericValueType(days.GoValue) == NumberValue {
+				if _, ok := wholeDays(days.GoValue); !ok {
+					return "", fmt.Errorf("dates can only be shifted by whole days, but got %v at %d:%d", days.GoValue, days.Line, days.Column)
+				}
+			}
+		}
+
+		return t, nil
+	case JSONExtract, JSONExtractText:
+		if left != JSONValue || (right != StringValue && right != NumberValue) {
+			return "", fmt.Errorf("json path can only be applied to 'json' with a 'string' or 'number', but got '%s' and '%s' at %d:%d", left, right, expr.Line, expr.Column)
+		}
+
+		if expr.Operator == JSONExtractText {
+			return StringValue, nil
+		}
+
+		return JSONValue, nil
+	case Index:
+		t, ok := ElementType(left)
+		if !ok || t == "" || right != NumberValue {
+			return "", fmt.Errorf("only arrays can be indexed, with a 'number', but got '%s' and '%s' at %d:%d", left, right, expr.Line, expr.Column)
+		}
+
+		return t, nil
+	case Contains, ContainedBy, Overlaps:
+		_, lok := ElementType(left)
+		_, rok := ElementType(right)
+		if !lok || !rok || !canCompare(left, right) {
+			return "", fmt.Errorf("operator '%s' can only be applied to arrays of the same type, but got '%s' and '%s' at %d:%d", expr.Operator, left, right, expr.Line, expr.Column)
+		}
+	default:
+		return "", fmt.Errorf("unknown operator '%s' at %d:%d", expr.Operator, expr.Line, expr.Column)
+	}
+
+	return BoolValue, nil
+}
+
+func (expr *Expression) inferCaseType(types map[string]ValueType) (ValueType, error) {
+	var subject ValueType
+	if expr.Subject != nil {
+		var err error
+		subject, err = InferType(expr.Subject, types)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	results := make([]*Expression, 0, len(expr.Branches)+1)
+	for _, b := range expr.Branches {
+		when, err := InferType(b.When, types)
+		if err != nil {
+			return "", err
+		}
+
+		if expr.Subject == nil && when != BoolValue {
+			return "", fmt.Errorf("WHEN condition of a CASE expression must be a boolean value, but got '%s' at %d:%d", when, b.When.Line, b.When.Column)
+		}
+
+		if expr.Subject != nil && when != subject {
+			return "", fmt.Errorf("WHEN value of a CASE expression must be of type '%s', but got '%s' at %d:%d", subject, when, b.When.Line, b.When.Column)
+		}
+
+		results = append(results, b.Then)
+	}
+
+	if expr.Else != nil {
+		results = append(results, expr.Else)
+	}
+
+	var result ValueType
+	for _, r := range results {
+		t, err := InferType(r, types)
+		if err != nil {
+			return "", err
+		}
+
+		if result != "" && t != result {
+			return "", fmt.Errorf("all results of a CASE expression must be of the same type, expected '%s', but got '%s' at %d:%d", result, t, r.Line, r.Column)
+		}
+
+		result = t
+	}
+
+	return result, nil
+}
+
+func (expr *Expression) inferFunctionType(types map[string]ValueType) (ValueType, error) {
+	f, ok := functions[expr.Function]
+	if !ok {
+		return "", fmt.Errorf("function '%s' does not exist at %d:%d", expr.Function, expr.Line, expr.Column)
+	}
+
+	args := make([]ValueType, len(expr.Args))
+	for i, a := range expr.Args {
+		t, err := InferType(a, types)
+		if err != nil {
+			return "", err
+		}
+		args[i] = t
+	}
+
+	t, err := f.returns(args)
+	if err != nil {
+		return "", fmt.Errorf("%w at %d:%d", err, expr.Line, expr.Column)
+	}
+
+	return t, nil
+}
+
+// canCompare tells if values of both types can be compared, dates
+// can be compared with timestamps as if they were at midnight, enums
+// with the strings holding their labels, and arrays with arrays whose
+// elements can be compared.
+func canCompare(left, right ValueType) bool {
+	// documents have no order, not even key order
+	if left == JSONValue || right == JSONValue {
+		return false
+	}
+
+	if left == right {
+		return true
+	}
+
+	if (left == EnumValue && right == StringValue) || (left == StringValue && right == EnumValue) {
+		return true
+	}
+
+	// arrays are compared element by element, the elements
+	// of empty array literals can be of any type
+	l, lok := ElementType(left)
+	r, rok := ElementType(right)
+	if lok && rok {
+		return l == "" || r == "" || canCompare(l, r)
+	}
+
+	isPointInTime := func(t ValueType) bool {
+		return t == DateValue || t == TimestampValue
+	}
+
+	return isPointInTime(left) && isPointInTime(right)
+}
+
+type arithmeticOperands struct {
+	left, right ValueType
+}
+
+// additions are commutative, so they are only listed in one order
+var (
+	additionTypes = map[arithmeticOperands]ValueType{
+		{NumberValue, NumberValue}:      NumberValue,
+		{DateValue, NumberValue}:        DateValue,
+		{DateValue, IntervalValue}:      TimestampValue,
+		{TimestampValue, IntervalValue}: TimestampValue,
+		{TimeValue, IntervalValue}:      TimeValue,
+		{IntervalValue, IntervalValue}:  IntervalValue,
+	}
+	subtractionTypes = map[arithmeticOperands]ValueType{
+		{NumberValue, NumberValue}:       NumberValue,
+		{DateValue, NumberValue}:         DateValue,
+		{DateValue, IntervalValue}:       TimestampValue,
+		{DateValue, DateValue}:           NumberValue,
+		{DateValue, TimestampValue}:      IntervalValue,
+		{TimestampValue, DateValue}:      IntervalValue,
+		{TimestampValue, TimestampValue}: IntervalValue,
+		{TimestampValue, IntervalValue}:  TimestampValue,
+		{TimeValue, TimeValue}:           IntervalValue,
+		{TimeValue, IntervalValue}:       TimeValue,
+		{IntervalValue, IntervalValue}:   IntervalValue,
+	}
+)
+
+func arithmeticType(operator OperatorType, left, right ValueType) (ValueType, bool) {
+	switch operator {
+	case Subtract:
+		t, ok := subtractionTypes[arithmeticOperands{left, right}]
+		return t, ok
+	case Multiply, Divide:
+		return NumberValue, left == NumberValue && right == NumberValue
+	}
+
+	if t, ok := additionTypes[arithmeticOperands{left, right}]; ok {
+		return t, true
+	}
+
+	t, ok := additionTypes[arithmeticOperands{right, left}]
+	return t, ok
+}
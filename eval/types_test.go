@@ -0,0 +1,256 @@
+package eval
+
+import "testing"
+
+func TestInferType(t *testing.T) {
+	types := map[string]ValueType{
+		"foo":  NumberValue,
+		"bar":  StringValue,
+		"baz":  BoolValue,
+		"day":  DateValue,
+		"doc":  JSONValue,
+		"tags": ArrayOf(StringValue),
+	}
+
+	tests := []struct {
+		name    string
+		expr    *Expression
+		want    ValueType
+		wantErr string
+	}{
+		{
+			name: "identifier",
+			expr: &Expression{Type: Operand, Identifier: "bar"},
+			want: StringValue,
+		},
+		{
+			name: "literal",
+			expr: &Expression{Type: Operand, GoValue: float64(1)},
+			want: NumberValue,
+		},
+		{
+			name: "comparison",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: GreaterThan,
+				Left:     &Expression{Type: Operand, Identifier: "foo"},
+				Right:    &Expression{Type: Operand, GoValue: float64(1)},
+			},
+			want: BoolValue,
+		},
+		{
+			name: "case",
+			expr: &Expression{
+				Type: Case,
+				Branches: []*CaseBranch{
+					{
+						When: &Expression{Type: Operand, Identifier: "baz"},
+						Then: &Expression{Type: Operand, Identifier: "bar"},
+					},
+				},
+				Else: &Expression{Type: Operand, GoValue: "none"},
+			},
+			want: StringValue,
+		},
+		{
+			name: "date plus interval",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Add,
+				Left:     &Expression{Type: Operand, GoValue: Interval{Days: 1}},
+				Right:    &Expression{Type: Operand, Identifier: "day"},
+			},
+			want: TimestampValue,
+		},
+		{
+			name: "function",
+			expr: &Expression{
+				Type:     Function,
+				Function: "extract",
+				Args: []*Expression{
+					{Type: Operand, GoValue: "year"},
+					{Type: Operand, Identifier: "day"},
+				},
+			},
+			want: NumberValue,
+		},
+		{
+			name: "interval minus date",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Subtract,
+				Left:     &Expression{Type: Operand, GoValue: Interval{Days: 1}},
+				Right:    &Expression{Type: Operand, Identifier: "day"},
+				Line:     1,
+				Column:   3,
+			},
+			wantErr: "operator 'subtract' can't be applied to 'interval' and 'date' at 1:3",
+		},
+		{
+			name: "date plus fractional days",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Add,
+				Left:     &Expression{Type: Operand, Identifier: "day"},
+				Right:    &Expression{Type: Operand, GoValue: 1.5, Line: 1, Column: 7},
+			},
+			wantErr: "dates can only be shifted by whole days, but got 1.5 at 1:7",
+		},
+		{
+			name: "json path as text",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: JSONExtractText,
+				Left: &Expression{
+					Type:     Operator,
+					Operator: JSONExtract,
+					Left:     &Expression{Type: Operand, Identifier: "doc"},
+					Right:    &Expression{Type: Operand, GoValue: "tags"},
+				},
+				Right: &Expression{Type: Operand, GoValue: int64(0)},
+			},
+			want: StringValue,
+		},
+		{
+			name: "json path on a string",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: JSONExtract,
+				Left:     &Expression{Type: Operand, Identifier: "bar"},
+				Right:    &Expression{Type: Operand, GoValue: "a"},
+				Line:     1,
+				Column:   4,
+			},
+			wantErr: "json path can only be applied to 'json' with a 'string' or 'number', but got 'string' and 'string' at 1:4",
+		},
+		{
+			name: "comparing documents",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Equal,
+				Left:     &Expression{Type: Operand, Identifier: "doc"},
+				Right:    &Expression{Type: Operand, Identifier: "doc"},
+				Line:     1,
+				Column:   5,
+			},
+			wantErr: "json documents can't be compared, extract a value with '->>' first at 1:5",
+		},
+		{
+			name: "array index",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Index,
+				Left:     &Expression{Type: Operand, Identifier: "tags"},
+				Right:    &Expression{Type: Operand, GoValue: int64(1)},
+			},
+			want: StringValue,
+		},
+		{
+			name: "comparison with any",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Equal,
+				Left:     &Expression{Type: Operand, GoValue: "go"},
+				Right:    &Expression{Type: Function, Function: "any", Args: []*Expression{{Type: Operand, Identifier: "tags"}}},
+			},
+			want: BoolValue,
+		},
+		{
+			name: "containment of an empty array",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Contains,
+				Left:     &Expression{Type: Operand, Identifier: "tags"},
+				Right:    &Expression{Type: Function, Function: "array", Args: []*Expression{}},
+			},
+			want: BoolValue,
+		},
+		{
+			name: "containment of another type",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Overlaps,
+				Left:     &Expression{Type: Operand, Identifier: "tags"},
+				Right:    &Expression{Type: Function, Function: "array", Args: []*Expression{{Type: Operand, Identifier: "foo"}}},
+				Line:     1,
+				Column:   6,
+			},
+			wantErr: "operator 'overlaps' can only be applied to arrays of the same type, but got 'string[]' and 'number[]' at 1:6",
+		},
+		{
+			name: "array of mixed types",
+			expr: &Expression{
+				Type:     Function,
+				Function: "array",
+				Args:     []*Expression{{Type: Operand, Identifier: "bar"}, {Type: Operand, Identifier: "foo"}},
+				Line:     1,
+				Column:   1,
+			},
+			wantErr: "all elements of an array must be of the same type, but got 'string' and 'number' at 1:1",
+		},
+		{
+			name: "operator without operands",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Multiply,
+				Left:     &Expression{Type: Operand, Identifier: "foo"},
+				Line:     1,
+				Column:   4,
+			},
+			wantErr: "operator 'multiply' is missing an operand at 1:4",
+		},
+		{
+			name:    "unknown identifier",
+			expr:    &Expression{Type: Operand, Identifier: "qux", Line: 2, Column: 3},
+			wantErr: "column 'qux' does not exist at 2:3",
+		},
+		{
+			name: "logical operation on numbers",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: Or,
+				Left:     &Expression{Type: Operand, Identifier: "baz"},
+				Right:    &Expression{Type: Operand, Identifier: "foo"},
+				Line:     1,
+				Column:   5,
+			},
+			wantErr: "both sides of a logical operation must be boolean values, but got 'bool' and 'number' at 1:5",
+		},
+		{
+			name: "error in nested expression",
+			expr: &Expression{
+				Type:     Operator,
+				Operator: And,
+				Left:     &Expression{Type: Operand, Identifier: "baz"},
+				Right: &Expression{
+					Type:     Operator,
+					Operator: LessThan,
+					Left:     &Expression{Type: Operand, Identifier: "bar"},
+					Right:    &Expression{Type: Operand, GoValue: true},
+					Line:     1,
+					Column:   12,
+				},
+			},
+			wantErr: "both sides of a comparison operation must be of the same type, but got 'string' and 'bool' at 1:12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InferType(tt.expr, types)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+
+			if gotErr != tt.wantErr {
+				t.Errorf("InferType() error = '%s', wantErr '%s'", gotErr, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("InferType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
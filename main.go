@@ -1,12 +1,92 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"path"
 
 	"github.com/jvitoroc/gobase/schema"
 )
 
+const usage = `usage: gobase check [directory]
+
+check verifies the checksums and the contents of every table
+and index file of the database in the directory, which is the
+current one when none is given, and reports the corruption found,
+along with the transactions of the write-ahead log that may not have
+reached the table files. Nothing is written to the database.
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "check" || len(os.Args) > 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	rootDir := "."
+	if len(os.Args) == 3 {
+		rootDir = os.Args[2]
+	}
+
+	found, err := check(context.Background(), os.Stdout, rootDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if found > 0 {
+		os.Exit(1)
+	}
+}
+
+// check verifies the database in the directory, writing every corruption
+// found to w, and gives how many there were.
+func check(ctx context.Context, w io.Writer, rootDir string) (int, error) {
+	if _, err := os.Stat(rootDir); err != nil {
+		return 0, err
+	}
+
+	// the files are only read, the log isn't replayed and
+	// nothing is created, so they're checked as they are
+	pool := schema.NewBufferPool(schema.DefaultBufferPoolPages)
+	defer pool.Close()
+
+	sch := schema.NewSchemaWithBufferPool(rootDir, pool)
+	if err := readSchema(rootDir, sch); err != nil {
+		return 0, err
+	}
+
+	found := 0
+	pending, err := schema.PendingTransactions(rootDir)
+	if err != nil {
+		return 0, err
+	}
+
+	if pending > 0 {
+		fmt.Fprintf(w, "write-ahead log '%s' has %d committed transactions that may not have reached the table files, opening the database writes them\n", path.Join(rootDir, schema.WALFileName), pending)
+		found++
+	}
+
+	corruptions, err := sch.Verify(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range corruptions {
+		fmt.Fprintln(w, c)
+	}
+	found += len(corruptions)
+
+	if found == 0 {
+		fmt.Fprintln(w, "no corruption found")
+	}
+
+	return found, nil
+}
+
 func storeSchema(sch *schema.Schema) {
 	file, err := os.OpenFile("schema", os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0666)
 	if err != nil {
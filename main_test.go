@@ -1 +1,110 @@
 package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jvitoroc/gobase/schema"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	database := database{}
+	if err := database.initialize(dir); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err := database.run(context.Background(), &bytes.Buffer{}, `
+		CREATE TABLE foo DEFINITIONS (id int, name string);
+		INSERT INTO foo VALUES (1, "a");
+		INSERT INTO foo VALUES (2, "b");
+	`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the log isn't emptied until the database is closed,
+	// which the check has to leave as it is
+	wal := filepath.Join(dir, schema.WALFileName)
+	before, err := os.ReadFile(wal)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	found, err := check(context.Background(), buf, dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if want := "write-ahead log '" + wal + "' has "; found != 1 || !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected the pending transactions to be reported with '%s', but got %d: %s", want, found, buf)
+	}
+
+	if after, err := os.ReadFile(wal); err != nil || !bytes.Equal(before, after) {
+		t.Errorf("expected the log to be left as it was, but got %d bytes instead of %d, %v", len(after), len(before), err)
+	}
+
+	if err := database.schema.BufferPool().Close(); err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf.Reset()
+	found, err = check(context.Background(), buf, dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if found != 0 || buf.String() != "no corruption found\n" {
+		t.Errorf("expected no corruption, but got %d: %s", found, buf)
+	}
+
+	file := filepath.Join(dir, strconv.FormatUint(uint64(database.schema.GetTable("foo").ID), 10))
+	if err := os.WriteFile(file, []byte("corrupted"), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf.Reset()
+	found, err = check(context.Background(), buf, dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if want := "file '" + file + "' is corrupted at offset 0: "; found != 1 || !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected a corruption starting with '%s', but got %d: %s", want, found, buf)
+	}
+}
+
+func TestCheckEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	buf := &bytes.Buffer{}
+	found, err := check(context.Background(), buf, dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if found != 0 || buf.String() != "no corruption found\n" {
+		t.Errorf("expected no corruption, but got %d: %s", found, buf)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected the check not to create any file, but got %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"github.com/jvitoroc/gobase/eval"
+	"github.com/jvitoroc/gobase/schema"
+)
+
+// indexScan is a range of an index holding every row a filter can be true for,
+// the rows read from it still have to be filtered.
+type indexScan struct {
+	index        *schema.Index
+	lower, upper *schema.IndexBound
+}
+
+type bound struct {
+	value     any
+	inclusive bool
+}
+
+// columnBounds holds the literals a column is compared with by a filter.
+type columnBounds struct {
+	equal        any
+	lower, upper *bound
+}
+
+// planIndexScan looks for the index whose first columns are narrowed down
+// the most by comparisons between a column and a literal that the filter
+// requires to be true, equalities narrow them down more than ranges. Hash
+// indexes are only used when all of their columns are compared for equality.
+// It gives nothing when no index can be used, so the table has to be scanned.
+func planIndexScan(t *schema.Table, filter *eval.Expression) *indexScan {
+	if filter == nil {
+		return nil
+	}
+
+	bounds := map[string]*columnBounds{}
+	for _, c := range conjuncts(filter) {
+		narrow(bounds, c)
+	}
+
+	var best *indexScan
+	bestScore := 0
+	for _, x := range t.ListIndexes() {
+		scan := &indexScan{
+			index: x,
+			lower: &schema.IndexBound{Inclusive: true},
+			upper: &schema.IndexBound{Inclusive: true},
+		}
+
+		score, equalities := 0, 0
+		for _, c := range x.Columns {
+			b := bounds[c.Name]
+			if b == nil {
+				break
+			}
+
+			if b.equal != nil {
+				scan.lower.Values = append(scan.lower.Values, b.equal)
+				scan.upper.Values = append(scan.upper.Values, b.equal)
+				score += 2
+				equalities++
+				continue
+			}
+
+			// a range ends the columns that can be
+			// used, since the ones after it aren't ordered
+			if b.lower != nil {
+				scan.lower.Values = append(scan.lower.Values, b.lower.value)
+				scan.lower.Inclusive = b.lower.inclusive
+			}
+
+			if b.upper != nil {
+				scan.upper.Values = append(scan.upper.Values, b.upper.value)
+				scan.upper.Inclusive = b.upper.inclusive
+			}
+
+			score++
+			break
+		}
+
+		// hash indexes can only find the values of all of their columns,
+		// which they do faster than a B+tree with the same columns
+		if x.Method == schema.HashIndex {
+			if equalities != len(x.Columns) {
+				continue
+			}
+			score++
+		}
+
+		if score <= bestScore {
+			continue
+		}
+
+		if len(scan.lower.Values) == 0 {
+			scan.lower = nil
+		}
+
+		if len(scan.upper.Values) == 0 {
+			scan.upper = nil
+		}
+
+		best, bestScore = scan, score
+	}
+
+	return best
+}
+
+// conjuncts splits the expression in the ones that are combined with AND.
+func conjuncts(expr *eval.Expression) []*eval.Expression {
+	if expr.Type == eval.Operator && expr.Operator == eval.And {
+		return append(conjuncts(expr.Left), conjuncts(expr.Right)...)
+	}
+
+	return []*eval.Expression{expr}
+}
+
+// narrow adds the comparison to the bounds of its column, when it's one
+// between a column and a literal. Optimized comparisons have their literal
+// on the right side.
+func narrow(bounds map[string]*columnBounds, expr *eval.Expression) {
+	if expr.Type != eval.Operator || expr.Left.Type != eval.Operand || expr.Left.Identifier == "" || !expr.Right.IsLiteral() {
+		return
+	}
+
+	// values that can't even be compared with themselves,
+	// such as ANY(...), can't be compared with the index
+	v := expr.Right.GoValue
+	if _, err := eval.Compare(v, v); err != nil {
+		return
+	}
+
+	b := bounds[expr.Left.Identifier]
+	if b == nil {
+		b = &columnBounds{}
+	}
+
+	switch expr.Operator {
+	case eval.Equal:
+		b.equal = v
+	case eval.GreaterThan, eval.GreaterEqualThan:
+		b.lower = tighter(b.lower, &bound{value: v, inclusive: expr.Operator == eval.GreaterEqualThan}, 1)
+	case eval.LessThan, eval.LessEqualThan:
+		b.upper = tighter(b.upper, &bound{value: v, inclusive: expr.Operator == eval.LessEqualThan}, -1)
+	default:
+		return
+	}
+
+	bounds[expr.Left.Identifier] = b
+}
+
+// tighter gives the bound that leaves fewer values out of a and b, direction
+// is 1 for lower bounds and -1 for upper bounds.
+func tighter(a, b *bound, direction int) *bound {
+	if a == nil {
+		return b
+	}
+
+	c, err := eval.Compare(b.value, a.value)
+	if err != nil {
+		return a
+	}
+
+	if c*direction > 0 || c == 0 && !b.inclusive {
+		return b
+	}
+
+	return a
+}
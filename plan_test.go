@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jvitoroc/gobase/eval"
+	"github.com/jvitoroc/gobase/schema"
+	"github.com/jvitoroc/gobase/sql"
+)
+
+func TestPlanIndexScan(t *testing.T) {
+	sch := schema.NewSchemaWithEngine(schema.NewMemoryEngine())
+	table, err := sch.CreateTable("foo", []*schema.NewColumn{
+		{Name: "a", Type: schema.Int64Type},
+		{Name: "b", Type: schema.StringType},
+		{Name: "c", Type: schema.Int64Type},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	indexes := []struct {
+		name    string
+		columns []string
+		method  schema.IndexMethod
+	}{
+		{name: "foo_a", columns: []string{"a"}, method: schema.BTreeIndex},
+		{name: "foo_b_c", columns: []string{"b", "c"}, method: schema.BTreeIndex},
+		{name: "foo_c", columns: []string{"c"}, method: schema.HashIndex},
+		{name: "foo_a_hash", columns: []string{"a"}, method: schema.HashIndex},
+	}
+
+	for _, x := range indexes {
+		if _, err := sch.CreateIndex(x.name, "foo", x.columns, x.method, false); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	type plan struct {
+		Index        string
+		Lower, Upper *schema.IndexBound
+	}
+
+	tests := []struct {
+		where    string
+		expected *plan
+	}{
+		{
+			where: `a == 1`,
+			expected: &plan{
+				Index: "foo_a_hash",
+				Lower: &schema.IndexBound{Values: []any{int64(1)}, Inclusive: true},
+				Upper: &schema.IndexBound{Values: []any{int64(1)}, Inclusive: true},
+			},
+		},
+		{
+			where: `a > 1 AND 10 >= a AND a > 3 AND b != "x"`,
+			expected: &plan{
+				Index: "foo_a",
+				Lower: &schema.IndexBound{Values: []any{int64(3)}},
+				Upper: &schema.IndexBound{Values: []any{int64(10)}, Inclusive: true},
+			},
+		},
+		{
+			where: `a < 5 AND b == "x" AND c < 3`,
+			expected: &plan{
+				Index: "foo_b_c",
+				Lower: &schema.IndexBound{Values: []any{"x"}, Inclusive: true},
+				Upper: &schema.IndexBound{Values: []any{"x", int64(3)}},
+			},
+		},
+		{
+			where: `b >= "m" AND c > 1`,
+			expected: &plan{
+				Index: "foo_b_c",
+				Lower: &schema.IndexBound{Values: []any{"m"}, Inclusive: true},
+			},
+		},
+		{
+			where: `c == 1`,
+			expected: &plan{
+				Index: "foo_c",
+				Lower: &schema.IndexBound{Values: []any{int64(1)}, Inclusive: true},
+				Upper: &schema.IndexBound{Values: []any{int64(1)}, Inclusive: true},
+			},
+		},
+		{where: `c > 1`},
+		{where: `a == 1 OR a == 2`},
+		{where: `a != 1`},
+		{where: `a == c`},
+	}
+
+	for _, test := range tests {
+		sts, err := sql.NewParser(`SELECT a FROM foo WHERE ` + test.where + `;`).Parse()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		scan := planIndexScan(table, eval.Optimize(sts[0].Clauses[2].Body.(*eval.Expression)))
+
+		var got *plan
+		if scan != nil {
+			got = &plan{Index: scan.index.Name, Lower: scan.lower, Upper: scan.upper}
+		}
+
+		if diff := cmp.Diff(test.expected, got); diff != "" {
+			t.Errorf("planning '%s' (-want +got):\n%s", test.where, diff)
+		}
+	}
+}
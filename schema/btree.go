@@ -0,0 +1,360 @@
+package schema
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// MaxIndexKeySize is the size of the largest key of an index, it's
+	// small enough for a page to hold a few keys, so that a node split
+	// in two always gives halves that fit in a page.
+	MaxIndexKeySize = (PageSize - pageHeaderSize) / 4
+
+	// the first page of an index file holds the page of the root of
+	// a B+tree, or the directory of a hash table, no node or bucket
+	// links to it, so it also stands for no page
+	btreeMetaPage = 0
+
+	rowIDSize = 6
+)
+
+// node is a node of a B+tree, stored in a slotted page: slot 0 holds the
+// kind of node and its link, the rest hold its keys in ascending order.
+//
+// Leaves link to the next leaf, or to page 0 when they're the last one,
+// and their keys end with the identifier of the row they point to.
+// Internal nodes link to their first child, each of their keys is
+// followed by the child holding the keys greater than or equal to it.
+type node struct {
+	leaf     bool
+	next     uint32
+	keys     [][]byte
+	children []uint32
+}
+
+func decodeNode(p page) (*node, error) {
+	header, err := p.row(0)
+	if err != nil || len(header) != 5 {
+		return nil, errors.New("index page has no node header")
+	}
+
+	n := &node{leaf: header[0] == 0}
+	link := binary.LittleEndian.Uint32(header[1:])
+	if n.leaf {
+		n.next = link
+	} else {
+		n.children = []uint32{link}
+	}
+
+	for slot := 1; slot < p.slots(); slot++ {
+		entry, err := p.row(slot)
+		if err != nil {
+			return nil, err
+		}
+
+		if n.leaf {
+			n.keys = append(n.keys, append([]byte(nil), entry...))
+			continue
+		}
+
+		if len(entry) < 4 {
+			return nil, fmt.Errorf("index entry %d is too short", slot)
+		}
+		n.children = append(n.children, binary.LittleEndian.Uint32(entry))
+		n.keys = append(n.keys, append([]byte(nil), entry[4:]...))
+	}
+
+	return n, nil
+}
+
+// encode writes the node to a page, it reports false when it doesn't fit.
+func (n *node) encode() (page, bool) {
+	p := newPage()
+
+	header := []byte{1, 0, 0, 0, 0}
+	if n.leaf {
+		header[0] = 0
+		binary.LittleEndian.PutUint32(header[1:], n.next)
+	} else {
+		binary.LittleEndian.PutUint32(header[1:], n.children[0])
+	}
+	p.insert(header)
+
+	for i, k := range n.keys {
+		entry := k
+		if !n.leaf {
+			entry = binary.LittleEndian.AppendUint32(nil, n.children[i+1])
+			entry = append(entry, k...)
+		}
+
+		if _, ok := p.insert(entry); !ok {
+			return nil, false
+		}
+	}
+
+	return p, true
+}
+
+// split moves the greater half of the node's keys, by size, to a new node.
+// For internal nodes the key in the middle is taken out of both of them,
+// since it's the one separating them in their parent.
+func (n *node) split() (*node, []byte) {
+	total := 0
+	for _, k := range n.keys {
+		total += len(k)
+	}
+
+	mid, size := 0, 0
+	for mid < len(n.keys)-1 && size+len(n.keys[mid]) <= total/2 {
+		size += len(n.keys[mid])
+		mid++
+	}
+	mid = max(mid, 1)
+
+	right := &node{leaf: n.leaf}
+	if n.leaf {
+		right.keys = append(right.keys, n.keys[mid:]...)
+		right.next = n.next
+		n.keys = n.keys[:mid]
+
+		return right, right.keys[0]
+	}
+
+	separator := n.keys[mid]
+	right.keys = append(right.keys, n.keys[mid+1:]...)
+	right.children = append(right.children, n.children[mid+1:]...)
+	n.keys = n.keys[:mid]
+	n.children = n.children[:mid+1]
+
+	return right, separator
+}
+
+// btree is a B+tree stored in a file of the buffer pool, whose keys are
+// ordered by compare. Changing the tree must be serialized by the caller.
+type btree struct {
+	pool    *BufferPool
+	file    string
+	compare func(a, b []byte) (int, error)
+}
+
+// create writes an empty tree to the file, which must be empty.
+func (b *btree) create(tx *tx) error {
+	meta, n, err := b.pool.allocate(b.file)
+	if err != nil {
+		return err
+	}
+	b.pool.unpin(meta)
+
+	if n != btreeMetaPage {
+		return fmt.Errorf("index file '%s' isn't empty", b.file)
+	}
+
+	root, err := b.allocate(tx, &node{leaf: true})
+	if err != nil {
+		return err
+	}
+
+	return b.setRoot(tx, root)
+}
+
+func (b *btree) root() (uint32, error) {
+	fr, err := b.pool.pin(b.file, btreeMetaPage)
+	if err != nil {
+		return 0, err
+	}
+	defer b.pool.unpin(fr)
+
+	fr.latch.RLock()
+	defer fr.latch.RUnlock()
+
+	root, err := fr.data.row(0)
+	if err != nil || len(root) != 4 {
+		return 0, fmt.Errorf("index file '%s' has no root", b.file)
+	}
+
+	return binary.LittleEndian.Uint32(root), nil
+}
+
+func (b *btree) setRoot(tx *tx, root uint32) error {
+	meta := newPage()
+	meta.insert(binary.LittleEndian.AppendUint32(nil, root))
+
+	return tx.writePage(b.file, btreeMetaPage, meta)
+}
+
+func (b *btree) read(n uint32) (*node, error) {
+	fr, err := b.pool.pin(b.file, n)
+	if err != nil {
+		return nil, err
+	}
+	defer b.pool.unpin(fr)
+
+	fr.latch.RLock()
+	defer fr.latch.RUnlock()
+
+	return decodeNode(fr.data)
+}
+
+func (b *btree) write(tx *tx, n uint32, nd *node) error {
+	p, ok := nd.encode()
+	if !ok {
+		return fmt.Errorf("node of index file '%s' doesn't fit in a page", b.file)
+	}
+
+	return tx.writePage(b.file, n, p)
+}
+
+func (b *btree) allocate(tx *tx, nd *node) (uint32, error) {
+	p, ok := nd.encode()
+	if !ok {
+		return 0, fmt.Errorf("node of index file '%s' doesn't fit in a page", b.file)
+	}
+
+	return tx.allocatePage(b.file, p)
+}
+
+// insert adds the key to the tree, growing it by a
+// level when the root has to be split.
+func (b *btree) insert(tx *tx, key []byte) error {
+	if len(key) > MaxIndexKeySize {
+		return fmt.Errorf("index key of %d bytes is too large, keys can have at most %d bytes", len(key), MaxIndexKeySize)
+	}
+
+	root, err := b.root()
+	if err != nil {
+		return err
+	}
+
+	right, separator, err := b.insertInto(tx, root, key)
+	if err != nil || right == 0 {
+		return err
+	}
+
+	newRoot, err := b.allocate(tx, &node{children: []uint32{root, right}, keys: [][]byte{separator}})
+	if err != nil {
+		return err
+	}
+
+	return b.setRoot(tx, newRoot)
+}
+
+// insertInto adds the key to the subtree of the node, when the node is split
+// it gives the page of the new node along with the key separating them.
+func (b *btree) insertInto(tx *tx, n uint32, key []byte) (uint32, []byte, error) {
+	nd, err := b.read(n)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	i, err := b.upperBound(nd.keys, key)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if nd.leaf {
+		nd.keys = slices.Insert(nd.keys, i, key)
+	} else {
+		right, separator, err := b.insertInto(tx, nd.children[i], key)
+		if err != nil || right == 0 {
+			return 0, nil, err
+		}
+
+		nd.keys = slices.Insert(nd.keys, i, separator)
+		nd.children = slices.Insert(nd.children, i+1, right)
+	}
+
+	if _, ok := nd.encode(); ok {
+		return 0, nil, b.write(tx, n, nd)
+	}
+
+	// the new node is written first, so the
+	// one linking to it never points nowhere
+	other, separator := nd.split()
+	right, err := b.allocate(tx, other)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if nd.leaf {
+		nd.next = right
+	}
+
+	return right, separator, b.write(tx, n, nd)
+}
+
+// upperBound gives the position of the first key greater than the given one.
+func (b *btree) upperBound(keys [][]byte, key []byte) (int, error) {
+	lo, hi := 0, len(keys)
+	for lo < hi {
+		mid := (lo + hi) / 2
+
+		c, err := b.compare(keys[mid], key)
+		if err != nil {
+			return 0, err
+		}
+
+		if c <= 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
+
+// scan yields the keys of the leaves in order, starting at the first leaf that
+// may hold keys that aren't below the bound, below tells if a key is below it.
+// The scan stops when yield reports false.
+func (b *btree) scan(below func(key []byte) (bool, error), yield func(key []byte) (bool, error)) error {
+	n, err := b.root()
+	if err != nil {
+		return err
+	}
+
+	nd, err := b.read(n)
+	if err != nil {
+		return err
+	}
+
+	for !nd.leaf {
+		// the child before the first key that isn't below the bound
+		// may still hold keys that aren't below it
+		i := 0
+		for i < len(nd.keys) {
+			ok, err := below(nd.keys[i])
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				break
+			}
+			i++
+		}
+
+		if nd, err = b.read(nd.children[i]); err != nil {
+			return err
+		}
+	}
+
+	for {
+		for _, k := range nd.keys {
+			more, err := yield(k)
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		if nd.next == btreeMetaPage {
+			return nil
+		}
+
+		if nd, err = b.read(nd.next); err != nil {
+			return err
+		}
+	}
+}
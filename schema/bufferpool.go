@@ -0,0 +1,463 @@
+package schema
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// DefaultBufferPoolPages is the amount of pages cached by the buffer
+// pool of a schema unless it's given another one, 8 MiB in total.
+const DefaultBufferPoolPages = 1024
+
+// BufferPool caches pages of table files in memory, it's shared by every
+// table of a schema. Pages are pinned while they're used, and only pages
+// that aren't pinned can be evicted, the least recently used first.
+// Pages are changed by transactions, which keep them pinned until they're
+// committed, when they're logged to the WAL and then written to their file,
+// so evicting a page never needs to write it.
+type BufferPool struct {
+	mu       sync.Mutex
+	capacity int
+	wal      *WAL
+
+	frames map[pageKey]*frame
+	files  map[string]*poolFile
+
+	// unpinned frames, from the least to the most recently used
+	lru *list.List
+
+	stats BufferPoolStats
+}
+
+type BufferPoolStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type pageKey struct {
+	file string
+	page uint32
+}
+
+// frame holds a page of the pool, latch must be held to read
+// or change the page, since other readers and writers share it.
+type frame struct {
+	key   pageKey
+	data  page
+	latch sync.RWMutex
+
+	pins int
+	elem *list.Element
+}
+
+type poolFile struct {
+	file  *os.File
+	pages uint32
+}
+
+func NewBufferPool(pages int) *BufferPool {
+	return &BufferPool{
+		capacity: max(pages, 1),
+		frames:   map[pageKey]*frame{},
+		files:    map[string]*poolFile{},
+		lru:      list.New(),
+	}
+}
+
+// SetWAL makes transactions log the pages they change to the WAL
+// before writing them, it must be set before the pool is used.
+func (b *BufferPool) SetWAL(w *WAL) {
+	b.wal = w
+}
+
+// Checkpoint makes sure every page written so far is on disk,
+// so the WAL doesn't need to keep them anymore.
+func (b *BufferPool) Checkpoint() error {
+	if b.wal == nil {
+		return b.sync()
+	}
+
+	return b.wal.checkpoint(b.sync)
+}
+
+// sync flushes every file open by the pool to disk.
+func (b *BufferPool) sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, f := range b.files {
+		if err := f.file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns how many times pages were found in the pool,
+// had to be read from disk, or were evicted to make room.
+func (b *BufferPool) Stats() BufferPoolStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stats
+}
+
+// pageCount returns the amount of pages of the file.
+func (b *BufferPool) pageCount(name string) (uint32, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := b.open(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return f.pages, nil
+}
+
+// pin returns the frame holding the page, reading it from
+// the file when it isn't cached, it must be unpinned once done.
+func (b *BufferPool) pin(name string, n uint32) (*frame, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := pageKey{file: name, page: n}
+	if fr, ok := b.frames[key]; ok {
+		b.stats.Hits++
+		b.retain(fr)
+		return fr, nil
+	}
+
+	b.stats.Misses++
+
+	f, err := b.open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if n >= f.pages {
+		return nil, fmt.Errorf("table file '%s' has no page %d", name, n)
+	}
+
+	if err := b.makeRoom(); err != nil {
+		return nil, err
+	}
+
+	p, err := readPage(f.file, n)
+	if err != nil {
+		return nil, err
+	}
+
+	fr := &frame{key: key, data: p, pins: 1}
+	b.frames[key] = fr
+
+	return fr, nil
+}
+
+// allocate adds an empty page to the end of the file and returns it pinned.
+func (b *BufferPool) allocate(name string) (*frame, uint32, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := b.open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := b.makeRoom(); err != nil {
+		return nil, 0, err
+	}
+
+	n := f.pages
+	p := newPage()
+	if err := writePage(f.file, n, p); err != nil {
+		return nil, 0, err
+	}
+	f.pages++
+
+	key := pageKey{file: name, page: n}
+	fr := &frame{key: key, data: p, pins: 1}
+	b.frames[key] = fr
+
+	return fr, n, nil
+}
+
+// unpin releases the frame, pages that were changed must be
+// released by committing the transaction that changed them.
+func (b *BufferPool) unpin(fr *frame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// frames that were discarded are left out of the LRU list,
+	// since they can't be evicted anymore
+	fr.pins--
+	if fr.pins == 0 && b.frames[fr.key] == fr {
+		fr.elem = b.lru.PushBack(fr)
+	}
+}
+
+// discard releases the frame and drops it from the pool,
+// so its page is read again from the file when needed.
+func (b *BufferPool) discard(fr *frame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fr.pins--
+	if b.frames[fr.key] == fr {
+		delete(b.frames, fr.key)
+	}
+}
+
+func (b *BufferPool) write(fr *frame) error {
+	b.mu.Lock()
+	f, ok := b.files[fr.key.file]
+	b.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("table file '%s' isn't open", fr.key.file)
+	}
+
+	fr.latch.RLock()
+	defer fr.latch.RUnlock()
+
+	return writePage(f.file, fr.key.page, fr.data)
+}
+
+// invalidate drops every page of the file and closes it, it must
+// be called after the file is replaced without using the pool.
+func (b *BufferPool) invalidate(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, fr := range b.frames {
+		if key.file != name {
+			continue
+		}
+
+		if fr.pins > 0 {
+			return fmt.Errorf("page %d of table file '%s' is in use", key.page, name)
+		}
+
+		b.lru.Remove(fr.elem)
+		delete(b.frames, key)
+	}
+
+	f, ok := b.files[name]
+	if !ok {
+		return nil
+	}
+
+	delete(b.files, name)
+
+	if err := f.file.Sync(); err != nil {
+		f.file.Close()
+		return err
+	}
+
+	return f.file.Close()
+}
+
+// Close closes every file opened by the pool.
+func (b *BufferPool) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var err error
+	for name, f := range b.files {
+		if serr := f.file.Sync(); serr != nil && err == nil {
+			err = serr
+		}
+		if cerr := f.file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(b.files, name)
+	}
+
+	clear(b.frames)
+	b.lru.Init()
+
+	if b.wal == nil {
+		return err
+	}
+
+	// the log is only emptied when every file was synced
+	if err == nil {
+		err = b.wal.checkpoint(func() error { return nil })
+	}
+
+	if cerr := b.wal.close(); cerr != nil && err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+func (b *BufferPool) retain(fr *frame) {
+	if fr.pins == 0 {
+		b.lru.Remove(fr.elem)
+		fr.elem = nil
+	}
+	fr.pins++
+}
+
+// makeRoom evicts the least recently used page when the pool is full.
+func (b *BufferPool) makeRoom() error {
+	if len(b.frames) < b.capacity {
+		return nil
+	}
+
+	e := b.lru.Front()
+	if e == nil {
+		return fmt.Errorf("buffer pool is full, all of its %d pages are pinned", b.capacity)
+	}
+
+	fr := b.lru.Remove(e).(*frame)
+	delete(b.frames, fr.key)
+	b.stats.Evictions++
+
+	return nil
+}
+
+func (b *BufferPool) open(name string) (*poolFile, error) {
+	if f, ok := b.files[name]; ok {
+		return f, nil
+	}
+
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := pageCount(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	f := &poolFile{file: file, pages: pages}
+	b.files[name] = f
+
+	return f, nil
+}
+
+// tx gathers the pages changed by an operation, which are kept pinned
+// until it's committed, so they can't be evicted before being logged.
+// Its pages are discarded when it's aborted, so the changes made to
+// them in memory are lost and the operation has no effect.
+type tx struct {
+	pool   *BufferPool
+	frames []*frame
+}
+
+func (b *BufferPool) begin() *tx {
+	return &tx{pool: b}
+}
+
+// dirty hands a pinned frame whose page was changed over to the transaction.
+func (t *tx) dirty(fr *frame) {
+	if slices.Contains(t.frames, fr) {
+		t.pool.unpin(fr)
+		return
+	}
+
+	t.frames = append(t.frames, fr)
+}
+
+// writePage replaces the page of the file with p.
+func (t *tx) writePage(file string, n uint32, p page) error {
+	fr, err := t.pool.pin(file, n)
+	if err != nil {
+		return err
+	}
+
+	fr.latch.Lock()
+	copy(fr.data, p)
+	fr.latch.Unlock()
+
+	t.dirty(fr)
+
+	return nil
+}
+
+// allocatePage appends p to the file and gives its page number.
+func (t *tx) allocatePage(file string, p page) (uint32, error) {
+	fr, n, err := t.pool.allocate(file)
+	if err != nil {
+		return 0, err
+	}
+
+	fr.latch.Lock()
+	copy(fr.data, p)
+	fr.latch.Unlock()
+
+	t.dirty(fr)
+
+	return n, nil
+}
+
+// commit logs the pages and writes them to their files, when
+// that fails the pages are discarded like when it's aborted.
+func (t *tx) commit() error {
+	if err := t.pool.commit(t.frames); err != nil {
+		t.abort()
+		return err
+	}
+
+	for _, fr := range t.frames {
+		t.pool.unpin(fr)
+	}
+	t.frames = nil
+
+	return nil
+}
+
+func (t *tx) abort() {
+	for _, fr := range t.frames {
+		t.pool.discard(fr)
+	}
+	t.frames = nil
+}
+
+// end commits the transaction when err is nil and aborts it otherwise.
+func (t *tx) end(err error) error {
+	if err != nil {
+		t.abort()
+		return err
+	}
+
+	return t.commit()
+}
+
+func (b *BufferPool) commit(frames []*frame) error {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	if b.wal == nil {
+		return b.writeFrames(frames)
+	}
+
+	full, err := b.wal.commit(frames, func() error {
+		return b.writeFrames(frames)
+	})
+	if err != nil || !full {
+		return err
+	}
+
+	return b.Checkpoint()
+}
+
+func (b *BufferPool) writeFrames(frames []*frame) error {
+	for _, fr := range frames {
+		if err := b.write(fr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
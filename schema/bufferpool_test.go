@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestBufferPool(t *testing.T) {
+	pool := NewBufferPool(2)
+	name := filepath.Join(t.TempDir(), "test.table")
+
+	for i := 0; i < 3; i++ {
+		tx := pool.begin()
+		fr, n, err := pool.allocate(name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if n != uint32(i) {
+			t.Errorf("expected page %d to be allocated, but got %d", i, n)
+		}
+
+		fr.data.insert([]byte(strconv.Itoa(i)))
+		tx.dirty(fr)
+		if err := tx.commit(); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	// page 0 was evicted when page 2 was allocated
+	if s := pool.Stats(); s.Evictions != 1 {
+		t.Errorf("expected 1 eviction, but got %d", s.Evictions)
+	}
+
+	tests := []struct {
+		page  uint32
+		stats BufferPoolStats
+	}{
+		{page: 2, stats: BufferPoolStats{Hits: 1, Evictions: 1}},
+		{page: 0, stats: BufferPoolStats{Hits: 1, Misses: 1, Evictions: 2}},
+		{page: 0, stats: BufferPoolStats{Hits: 2, Misses: 1, Evictions: 2}},
+		{page: 1, stats: BufferPoolStats{Hits: 2, Misses: 2, Evictions: 3}},
+	}
+
+	for _, test := range tests {
+		fr, err := pool.pin(name, test.page)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		row, err := fr.data.row(0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if string(row) != strconv.Itoa(int(test.page)) {
+			t.Errorf("expected page %d to hold '%d', but got '%s'", test.page, test.page, row)
+		}
+
+		pool.unpin(fr)
+
+		if s := pool.Stats(); s != test.stats {
+			t.Errorf("expected stats %+v after reading page %d, but got %+v", test.stats, test.page, s)
+		}
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBufferPoolPinned(t *testing.T) {
+	pool := NewBufferPool(1)
+	defer pool.Close()
+
+	name := filepath.Join(t.TempDir(), "test.table")
+
+	fr, _, err := pool.allocate(name)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, _, err := pool.allocate(name); err == nil || err.Error() != "buffer pool is full, all of its 1 pages are pinned" {
+		t.Errorf("expected the pool to be full, but got %v", err)
+	}
+
+	if err := pool.invalidate(name); err == nil {
+		t.Error("expected pinned pages not to be invalidated")
+	}
+
+	pool.unpin(fr)
+
+	if _, _, err := pool.allocate(name); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTableBufferPool(t *testing.T) {
+	table := newTestTable(t)
+	fileStorageOf(table).pool = NewBufferPool(1)
+	defer fileStorageOf(table).pool.Close()
+
+	ids := make([]RowID, 20)
+	for i := range ids {
+		id, err := storeRow(table, []string{strconv.Itoa(i), "row"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ids[i] = id
+	}
+
+	before := fileStorageOf(table).pool.Stats()
+
+	for i, id := range ids {
+		r, err := table.Fetch(id)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if v := r.Values()[0]; v != int64(i) {
+			t.Errorf("expected row %s to hold %d, but got %v", id, i, v)
+		}
+	}
+
+	// every row fits in the first page, which stays cached
+	if s := fileStorageOf(table).pool.Stats(); s.Misses != before.Misses || s.Hits != before.Hits+uint64(len(ids)) {
+		t.Errorf("expected every fetch to hit the pool, but got %+v after %+v", s, before)
+	}
+
+	if rows := readAll(t, table); len(rows) != len(ids) {
+		t.Errorf("expected %d rows, but got %d", len(ids), len(rows))
+	}
+}
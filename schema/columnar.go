@@ -0,0 +1,458 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Layout is how the rows of a table are laid out in its pages.
+type Layout string
+
+const (
+	// rows are stored one after the other, each value
+	// prefixed by the id of its column and its size
+	RowLayout Layout = ""
+
+	// every page holds a group of rows as one segment per column, so
+	// scans only decode the segments of the columns they need
+	ColumnarLayout Layout = "columnar"
+)
+
+func CheckLayout(l Layout) error {
+	if l != RowLayout && l != ColumnarLayout {
+		return fmt.Errorf("layout '%s' does not exist", l)
+	}
+
+	return nil
+}
+
+// columnarMaxRows is how many rows a page of a columnar
+// table can hold, as many as the slot of a RowID tells apart.
+const columnarMaxRows = math.MaxUint16 + 1
+
+// columnarGroupRows is how many rows are added to the group of a page
+// before a new one is started. Every insert decodes and encodes the group
+// of the last page again, so filling a group takes time quadratic in its
+// rows. Values that repeat a lot could fit many more of them in a page,
+// but the little space they would save isn't worth inserts that slow.
+const columnarGroupRows = 1024
+
+// columnGroup is the group of rows of a page of a columnar table, it
+// only holds the values of the columns whose segments were decoded.
+type columnGroup struct {
+	n      uint32
+	page   page
+	rows   int
+	values map[uint32][][]byte
+}
+
+var errIncompleteSegment = errors.New("segment is incomplete")
+
+// encodeSegment writes the values of a column as a dictionary of its distinct
+// values, followed by the runs of rows holding the same one of them:
+//
+//	column id | rows | values | size, value ... | position, length ...
+//
+// every number but the column id is a uvarint.
+func encodeSegment(id uint32, values [][]byte) []byte {
+	var dictionary [][]byte
+	positions := map[string]int{}
+
+	segment := binary.LittleEndian.AppendUint32(nil, id)
+	segment = binary.AppendUvarint(segment, uint64(len(values)))
+
+	var runs []byte
+	for i := 0; i < len(values); {
+		position, ok := positions[string(values[i])]
+		if !ok {
+			position = len(dictionary)
+			positions[string(values[i])] = position
+			dictionary = append(dictionary, values[i])
+		}
+
+		length := 1
+		for i+length < len(values) && bytes.Equal(values[i], values[i+length]) {
+			length++
+		}
+
+		runs = binary.AppendUvarint(runs, uint64(position))
+		runs = binary.AppendUvarint(runs, uint64(length))
+		i += length
+	}
+
+	segment = binary.AppendUvarint(segment, uint64(len(dictionary)))
+	for _, v := range dictionary {
+		segment = binary.AppendUvarint(segment, uint64(len(v)))
+		segment = append(segment, v...)
+	}
+
+	return append(segment, runs...)
+}
+
+// decodeSegment gives the column id and the values of the segment,
+// which are part of it.
+func decodeSegment(segment []byte) (uint32, [][]byte, error) {
+	if len(segment) < 4 {
+		return 0, nil, errIncompleteSegment
+	}
+
+	id := binary.LittleEndian.Uint32(segment)
+	segment = segment[4:]
+
+	uvarint := func() (int, bool) {
+		v, n := binary.Uvarint(segment)
+		if n <= 0 || v > math.MaxInt32 {
+			return 0, false
+		}
+		segment = segment[n:]
+
+		return int(v), true
+	}
+
+	rows, ok := uvarint()
+	if !ok {
+		return 0, nil, errIncompleteSegment
+	}
+
+	if rows > columnarMaxRows {
+		return 0, nil, fmt.Errorf("segment has %d rows, but pages hold at most %d", rows, columnarMaxRows)
+	}
+
+	size, ok := uvarint()
+	if !ok {
+		return 0, nil, errIncompleteSegment
+	}
+
+	dictionary := make([][]byte, 0, min(size, len(segment)))
+	for i := 0; i < size; i++ {
+		n, ok := uvarint()
+		if !ok || n > len(segment) {
+			return 0, nil, errIncompleteSegment
+		}
+
+		dictionary = append(dictionary, segment[:n])
+		segment = segment[n:]
+	}
+
+	values := make([][]byte, 0, rows)
+	for len(segment) > 0 {
+		position, ok := uvarint()
+		if !ok {
+			return 0, nil, errIncompleteSegment
+		}
+
+		length, ok := uvarint()
+		if !ok {
+			return 0, nil, errIncompleteSegment
+		}
+
+		if position >= len(dictionary) {
+			return 0, nil, fmt.Errorf("segment has no value %d", position)
+		}
+
+		if length > rows-len(values) {
+			return 0, nil, fmt.Errorf("segment has more than %d rows", rows)
+		}
+
+		for j := 0; j < length; j++ {
+			values = append(values, dictionary[position])
+		}
+	}
+
+	if len(values) != rows {
+		return 0, nil, fmt.Errorf("segment has %d rows, but %d were expected", len(values), rows)
+	}
+
+	return id, values, nil
+}
+
+// encodeGroup writes the segment of every column to a new page,
+// it reports false when they don't fit in it.
+func (t *Table) encodeGroup(values map[uint32][][]byte) (page, bool, error) {
+	p := newPage()
+	for _, c := range t.Columns {
+		segment, err := t.compressBlock(encodeSegment(c.ID, values[c.ID]))
+		if err != nil {
+			return nil, false, err
+		}
+
+		if _, ok := p.insert(segment); !ok {
+			return nil, false, nil
+		}
+	}
+
+	return p, true, nil
+}
+
+// decodeSegments decodes the segments of the given columns that
+// weren't decoded yet, the segment of the first column is decoded
+// when none is given, since the rows are only known from one.
+func (t *Table) decodeSegments(g *columnGroup, columns []*Column) error {
+	if g.values == nil {
+		g.values = map[uint32][][]byte{}
+	}
+
+	if len(columns) == 0 && len(g.values) == 0 {
+		columns = t.Columns[:1]
+	}
+
+	for _, c := range columns {
+		if _, ok := g.values[c.ID]; ok {
+			continue
+		}
+
+		slot := slices.IndexFunc(t.Columns, func(tc *Column) bool { return tc.ID == c.ID })
+
+		data, err := g.page.row(slot)
+		if err != nil {
+			return err
+		}
+
+		segment, err := t.decompressBlock(data)
+		if err != nil {
+			return err
+		}
+
+		id, values, err := decodeSegment(segment)
+		if err != nil {
+			return fmt.Errorf("segment of column '%s': %w", c.Name, err)
+		}
+
+		if id != c.ID {
+			return fmt.Errorf("segment of column '%s' belongs to column %d", c.Name, id)
+		}
+
+		if len(g.values) > 0 && len(values) != g.rows {
+			return fmt.Errorf("segment of column '%s' has %d rows, but %d were expected", c.Name, len(values), g.rows)
+		}
+
+		g.rows = len(values)
+		g.values[c.ID] = values
+	}
+
+	return nil
+}
+
+// row gives the stored values of the row of the group, holding only the
+// decoded columns.
+func (g *columnGroup) row(i int) map[uint32][]byte {
+	blobs := make(map[uint32][]byte, len(g.values))
+	for id, values := range g.values {
+		blobs[id] = values[i]
+	}
+
+	return blobs
+}
+
+// writeColumnar adds the row to the group of the last page of the table
+// file, encoding the whole page again, or to a new page when the row doesn't
+// fit in the last one anymore.
+func (s *fileStorage) writeColumnar(tx *tx, blobs map[uint32][]byte) (RowID, error) {
+	pages, err := s.pool.pageCount(s.fileName())
+	if err != nil {
+		return RowID{}, err
+	}
+
+	if pages > 0 {
+		fr, err := s.pool.pin(s.fileName(), pages-1)
+		if err != nil {
+			return RowID{}, err
+		}
+
+		fr.latch.Lock()
+		slot, ok, err := s.table.appendToGroup(&columnGroup{n: pages - 1, page: fr.data}, blobs)
+		fr.latch.Unlock()
+
+		if err != nil {
+			s.pool.unpin(fr)
+			return RowID{}, fmt.Errorf("page %d: %w", pages-1, err)
+		}
+
+		if ok {
+			tx.dirty(fr)
+			return RowID{Page: pages - 1, Slot: slot}, nil
+		}
+		s.pool.unpin(fr)
+	}
+
+	group := map[uint32][][]byte{}
+	for _, c := range s.table.Columns {
+		group[c.ID] = [][]byte{blobs[c.ID]}
+	}
+
+	p, ok, err := s.table.encodeGroup(group)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	if !ok {
+		return RowID{}, errors.New("row doesn't fit in a page once its columns are encoded")
+	}
+
+	n, err := tx.allocatePage(s.fileName(), p)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	return RowID{Page: n}, nil
+}
+
+// appendToGroup encodes the page again with the row added to its group,
+// it reports false when the group is full or the page can't hold it.
+func (t *Table) appendToGroup(g *columnGroup, blobs map[uint32][]byte) (uint16, bool, error) {
+	if err := t.decodeSegments(g, t.Columns); err != nil {
+		return 0, false, err
+	}
+
+	if g.rows >= columnarGroupRows {
+		return 0, false, nil
+	}
+
+	for _, c := range t.Columns {
+		g.values[c.ID] = append(g.values[c.ID], blobs[c.ID])
+	}
+
+	p, ok, err := t.encodeGroup(g.values)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	copy(g.page, p)
+
+	return uint16(g.rows), true, nil
+}
+
+// readGroups decodes the given columns of the group of every page, the pages
+// are copied, since their segments can be decoded once they're unpinned.
+func (s *fileStorage) readGroups(ctx context.Context, columns []*Column, yield func(*columnGroup) error) error {
+	pages, err := s.pool.pageCount(s.fileName())
+	if err != nil {
+		return s.corrupted(err)
+	}
+
+	for n := uint32(0); n < pages; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fr, err := s.pool.pin(s.fileName(), n)
+		if err != nil {
+			return s.corrupted(err)
+		}
+
+		fr.latch.RLock()
+		g := &columnGroup{n: n, page: bytes.Clone(fr.data)}
+		fr.latch.RUnlock()
+		s.pool.unpin(fr)
+
+		if err := s.table.decodeSegments(g, columns); err != nil {
+			return fmt.Errorf("page %d: %w", n, err)
+		}
+
+		if err := yield(g); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readColumns gives the stored values of the given columns of every row,
+// columnar tables only decode those columns.
+func (s *fileStorage) readColumns(ctx context.Context, columns []*Column, yield func(RowID, map[uint32][]byte) error) error {
+	if s.table.Layout == ColumnarLayout {
+		return s.readGroups(ctx, columns, func(g *columnGroup) error {
+			for i := 0; i < g.rows; i++ {
+				if err := yield(RowID{Page: g.n, Slot: uint16(i)}, g.row(i)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return s.readPages(ctx, func(r storedRow) error {
+		blobs, err := s.table.storedColumns(r.data)
+		if err != nil {
+			return err
+		}
+
+		return yield(r.id, blobs)
+	})
+}
+
+// scanColumnar gives include the rows of a columnar table holding only the
+// given columns, the rest of the columns of a page are only decoded when it
+// includes some of its rows.
+func (s *fileStorage) scanColumnar(ctx context.Context, columns []*Column, include func(RowID, map[uint32][]byte) (bool, error), yield func(RowID, map[uint32][]byte) error) error {
+	return s.readGroups(ctx, columns, func(g *columnGroup) error {
+		var included []int
+		for i := 0; i < g.rows; i++ {
+			ok, err := include(RowID{Page: g.n, Slot: uint16(i)}, g.row(i))
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				included = append(included, i)
+			}
+		}
+
+		if len(included) == 0 {
+			return nil
+		}
+
+		if err := s.table.decodeSegments(g, s.table.Columns); err != nil {
+			return fmt.Errorf("page %d: %w", g.n, err)
+		}
+
+		for _, i := range included {
+			if err := yield(RowID{Page: g.n, Slot: uint16(i)}, g.row(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// fetchColumnar gives the row of the group in the page, which must be
+// latched, its values are copied, since they're part of the page.
+func (s *fileStorage) fetchColumnar(p page, id RowID) (map[uint32][]byte, error) {
+	g := &columnGroup{n: id.Page, page: p}
+	if err := s.table.decodeSegments(g, s.table.Columns); err != nil {
+		return nil, fmt.Errorf("page %d: %w", id.Page, err)
+	}
+
+	if int(id.Slot) >= g.rows {
+		return nil, fmt.Errorf("row %s: page has no row %d", id, id.Slot)
+	}
+
+	blobs := g.row(int(id.Slot))
+	for c, v := range blobs {
+		blobs[c] = bytes.Clone(v)
+	}
+
+	return blobs, nil
+}
+
+// checkGroup decodes every value of the group in the page.
+func (t *Table) checkGroup(n uint32, p page) error {
+	g := &columnGroup{n: n, page: p}
+	if err := t.decodeSegments(g, t.Columns); err != nil {
+		return err
+	}
+
+	for i := 0; i < g.rows; i++ {
+		if _, err := t.deserializeRow(RowID{Page: n, Slot: uint16(i)}, g.row(i)); err != nil {
+			return fmt.Errorf("row %s: %w", RowID{Page: n, Slot: uint16(i)}, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestColumnarTable(t *testing.T) {
+	for _, compression := range []Compression{NoCompression, FlateCompression} {
+		rowTable := newTestTable(t)
+		table := newTestTable(t)
+		table.Layout = ColumnarLayout
+		table.Compression = compression
+
+		const rows = 1200
+		for i := 0; i < rows; i++ {
+			values := []string{strconv.Itoa(i), "status " + strconv.Itoa(i/100%3)}
+			for _, tb := range []*Table{rowTable, table} {
+				if err := tb.Insert(values); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		rowPages, err := fileStorageOf(rowTable).pool.pageCount(fileStorageOf(rowTable).fileName())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pages, err := fileStorageOf(table).pool.pageCount(fileStorageOf(table).fileName())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pages*2 > rowPages {
+			t.Errorf("expected the columnar table to take at least 2 times fewer pages, but got %d pages instead of %d", pages, rowPages)
+		}
+
+		got := readAll(t, table)
+		if len(got) != rows {
+			t.Fatalf("expected %d rows, but got %d", rows, len(got))
+		}
+
+		for i, r := range readAll(t, rowTable) {
+			if w, g := r.Values(), got[i].Values(); w[0] != g[0] || w[1] != g[1] {
+				t.Errorf("expected row %d to hold %v, but got %v", i, w, g)
+			}
+		}
+
+		x, err := table.createIndex("test_s", []string{"s"}, HashIndex, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bound := &IndexBound{Values: []any{"status 1"}, Inclusive: true}
+		if found := scanIndex(t, table, x, bound, bound); len(found) != rows/3 {
+			t.Errorf("expected the index to find %d rows, but got %d", rows/3, len(found))
+		}
+
+		corruptions, err := table.Verify(context.Background())
+		if err != nil || len(corruptions) != 0 {
+			t.Errorf("expected no corruption, but got %v, %v", corruptions, err)
+		}
+	}
+}
+
+func TestColumnarRead(t *testing.T) {
+	table := newTestTable(t)
+	table.Layout = ColumnarLayout
+
+	for i := 0; i < 10; i++ {
+		if err := table.Insert([]string{strconv.Itoa(i), "row " + strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only the column read by the filter is decoded for it,
+	// the rows it includes are written with all of their columns
+	buf := &bytes.Buffer{}
+	err := table.Read(context.Background(), buf, []string{"n"}, func(r *DeserializedRow) (bool, error) {
+		if r.Values()[1] != nil {
+			t.Errorf("expected column s not to be decoded, but got %v", r.Values()[1])
+		}
+
+		return r.Values()[0] == int64(7), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"Value":7}`) || !strings.Contains(buf.String(), `"Value":"row 7"}`) {
+		t.Errorf("expected row 7 to be written, but got %s", buf.String())
+	}
+
+	if n := strings.Count(buf.String(), `{"Columns"`); n != 1 {
+		t.Errorf("expected 1 row to be written, but got %d", n)
+	}
+}
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		values [][]byte
+		size   int
+	}{
+		{values: [][]byte{}, size: 6},
+		{values: [][]byte{[]byte("a"), []byte("a"), []byte("a"), []byte("a")}, size: 10},
+		{values: [][]byte{[]byte("a"), []byte("b"), []byte("a"), {}}, size: 19},
+	}
+
+	for _, test := range tests {
+		segment := encodeSegment(3, test.values)
+		if len(segment) != test.size {
+			t.Errorf("expected %q to be encoded in %d bytes, but got %d", test.values, test.size, len(segment))
+		}
+
+		id, values, err := decodeSegment(segment)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if id != 3 || !slices.EqualFunc(values, test.values, bytes.Equal) {
+			t.Errorf("expected column 3 to hold %q, but got column %d holding %q", test.values, id, values)
+		}
+
+		if _, _, err := decodeSegment(segment[:len(segment)-1]); err == nil {
+			t.Errorf("expected the segment of %q to be incomplete once truncated", test.values)
+		}
+	}
+}
+
+func TestColumnarGroupRows(t *testing.T) {
+	table := newTestTable(t)
+	table.Layout = ColumnarLayout
+
+	// the rows are all the same, so a page could hold
+	// many more of them than a group is allowed to
+	for i := 0; i < columnarGroupRows+1; i++ {
+		if err := table.Insert([]string{"1", "same"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pages, err := fileStorageOf(table).pool.pageCount(fileStorageOf(table).fileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pages != 2 {
+		t.Errorf("expected the rows to take 2 pages, but got %d", pages)
+	}
+
+	if got := readAll(t, table); len(got) != columnarGroupRows+1 {
+		t.Errorf("expected %d rows, but got %d", columnarGroupRows+1, len(got))
+	}
+}
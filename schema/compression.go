@@ -0,0 +1,209 @@
+package schema
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// Compression is the algorithm the rows of a table are compressed with.
+type Compression string
+
+const (
+	NoCompression    Compression = ""
+	FlateCompression Compression = "flate"
+)
+
+// TableOptions are the settings a table is created with.
+type TableOptions struct {
+	Compression Compression
+	Layout      Layout
+}
+
+func CheckCompression(c Compression) error {
+	if c != NoCompression && c != FlateCompression {
+		return fmt.Errorf("compression '%s' does not exist", c)
+	}
+
+	return nil
+}
+
+// the rows of a page of a compressed table are compressed together in a
+// single block, so whatever they have in common is only stored once. Blocks
+// start with a byte telling how the rest of them is stored, blocks that
+// compression wouldn't make smaller are raw.
+const (
+	rawBlock   = 0
+	flateBlock = 1
+
+	// maxBlockSize is the most a block can hold before it's compressed,
+	// which keeps the block of the last page quick to compress again
+	// every time a row is added to it.
+	maxBlockSize = 8 * PageSize
+)
+
+var (
+	flateWriters = sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return w
+	}}
+	flateReaders = sync.Pool{New: func() any {
+		return flate.NewReader(nil)
+	}}
+)
+
+// compressBlock compresses the data when the table is compressed.
+func (t *Table) compressBlock(data []byte) ([]byte, error) {
+	if t.Compression == NoCompression {
+		return data, nil
+	}
+
+	buf := bytes.NewBuffer([]byte{flateBlock})
+
+	w := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(w)
+
+	w.Reset(buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	if buf.Len() >= 1+len(data) {
+		return append([]byte{rawBlock}, data...), nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompressBlock gives back the data stored by compressBlock.
+func (t *Table) decompressBlock(block []byte) ([]byte, error) {
+	if t.Compression == NoCompression {
+		return block, nil
+	}
+
+	if len(block) == 0 {
+		return nil, errors.New("compressed block is empty")
+	}
+
+	switch block[0] {
+	case rawBlock:
+		return block[1:], nil
+	case flateBlock:
+		r := flateReaders.Get().(io.ReadCloser)
+		defer flateReaders.Put(r)
+
+		if err := r.(flate.Resetter).Reset(bytes.NewReader(block[1:]), nil); err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decompress block: %w", err)
+		}
+
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("block is stored in unknown format %d", block[0])
+}
+
+// pageRows gives the rows stored in the page, which are part of it
+// unless the table is compressed and they're decoded from its block.
+func (t *Table) pageRows(p page) ([][]byte, error) {
+	if t.Compression == NoCompression {
+		rows := make([][]byte, p.slots())
+		for slot := range rows {
+			row, err := p.row(slot)
+			if err != nil {
+				return nil, err
+			}
+			rows[slot] = row
+		}
+
+		return rows, nil
+	}
+
+	if p.slots() == 0 {
+		return nil, nil
+	}
+
+	block, err := p.row(0)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := t.decompressBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errors.New("size of row in block is incomplete")
+		}
+
+		size := binary.LittleEndian.Uint32(data)
+		if uint64(size) > uint64(len(data)-4) {
+			return nil, fmt.Errorf("row of %d bytes goes past the end of the block", size)
+		}
+
+		rows = append(rows, data[4:4+size])
+		data = data[4+size:]
+	}
+
+	return rows, nil
+}
+
+// appendRow adds the row to the page, giving its slot. The block of a page
+// of a compressed table is encoded again along with the row. It reports false
+// when the row doesn't fit in the page.
+func (t *Table) appendRow(p page, row []byte) (uint16, bool, error) {
+	if t.Compression == NoCompression {
+		slot, ok := p.insert(row)
+		return slot, ok, nil
+	}
+
+	rows, err := t.pageRows(p)
+	if err != nil {
+		return 0, false, err
+	}
+
+	size := 0
+	for _, r := range rows {
+		size += 4 + len(r)
+	}
+
+	// a row is always given a page, however large it is
+	if len(rows) > 0 && (size+4+len(row) > maxBlockSize || len(rows) > math.MaxUint16) {
+		return 0, false, nil
+	}
+
+	data := make([]byte, 0, size+4+len(row))
+	for _, r := range append(rows, row) {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(r)))
+		data = append(data, r...)
+	}
+
+	block, err := t.compressBlock(data)
+	if err != nil {
+		return 0, false, err
+	}
+
+	encoded := newPage()
+	if _, ok := encoded.insert(block); !ok {
+		return 0, false, nil
+	}
+	copy(p, encoded)
+
+	return uint16(len(rows)), true, nil
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	plain := newTestTable(t)
+	compressed := newTestTable(t)
+	compressed.Compression = FlateCompression
+
+	const rows = 500
+	for i := 0; i < rows; i++ {
+		values := []string{strconv.Itoa(i), strings.Repeat("row "+strconv.Itoa(i%10)+" of a very compressible table, ", 8)}
+		for _, table := range []*Table{plain, compressed} {
+			if err := table.Insert(values); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	plainFile, err := os.Stat(fileStorageOf(plain).fileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressedFile, err := os.Stat(fileStorageOf(compressed).fileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rows are compressed along with the others of their page,
+	// so what they have in common is only stored once per page
+	if compressedFile.Size()*5 > plainFile.Size() {
+		t.Errorf("expected compression to make the file at least 5 times smaller, but got %d bytes instead of %d", compressedFile.Size(), plainFile.Size())
+	}
+
+	// rows too large for a page are stored once compressed
+	if err := compressed.Insert([]string{"-1", strings.Repeat("x", 2*PageSize)}); err != nil {
+		t.Error(err)
+	}
+
+	got := readAll(t, compressed)
+	if len(got) != rows+1 {
+		t.Fatalf("expected %d rows, but got %d", rows+1, len(got))
+	}
+
+	want := readAll(t, plain)
+	for i := range want {
+		if w, g := want[i].Values(), got[i].Values(); w[0] != g[0] || w[1] != g[1] {
+			t.Errorf("expected row %d to hold %v, but got %v", i, w, g)
+		}
+	}
+
+	x, err := compressed.createIndex("test_n", []string{"n"}, BTreeIndex, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bound := &IndexBound{Values: []any{int64(-1)}, Inclusive: true}
+	if got := scanIndex(t, compressed, x, bound, bound); len(got) != 1 {
+		t.Errorf("expected the index to find the large row, but got %v", got)
+	}
+
+	found, err := compressed.Verify(context.Background())
+	if err != nil || len(found) != 0 {
+		t.Errorf("expected no corruption, but got %v, %v", found, err)
+	}
+}
+
+func TestCompressBlock(t *testing.T) {
+	table := &Table{Compression: FlateCompression}
+
+	random := make([]byte, 100)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		data   []byte
+		format byte
+	}{
+		{data: random, format: rawBlock},
+		{data: []byte(strings.Repeat("a", 100)), format: flateBlock},
+		{data: []byte{}, format: rawBlock},
+	}
+
+	for _, test := range tests {
+		block, err := table.compressBlock(test.data)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if block[0] != test.format {
+			t.Errorf("expected a block of %d bytes to be stored in format %d, but got %d", len(test.data), test.format, block[0])
+		}
+
+		data, err := table.decompressBlock(block)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if string(data) != string(test.data) {
+			t.Errorf("expected the block to be decompressed to %q, but got %q", test.data, data)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package schema
+
+import "context"
+
+// StorageEngine keeps the rows of the tables of a schema.
+type StorageEngine interface {
+	// OpenTable gives the storage of the rows of the table.
+	OpenTable(t *Table) TableStorage
+
+	// Verify checks the data the engine keeps apart from
+	// the storage of the given tables, which check their own.
+	Verify(ctx context.Context, tables []*Table) ([]*CorruptionError, error)
+}
+
+// TableStorage keeps the rows of a table along with the keys of its indexes.
+// Rows are handed over as the values of their columns encoded the way the
+// columns store them, by the identifiers of the columns. The table serializes
+// the writes to its storage, reads can happen along with them.
+type TableStorage interface {
+	// Insert stores the row and adds the keys to the indexes of the table,
+	// given in the same order, the row is only stored along with its keys.
+	Insert(row map[uint32][]byte, keys [][]byte) (RowID, error)
+
+	// Fetch gives the row with the identifier.
+	Fetch(id RowID) (map[uint32][]byte, error)
+
+	// Scan yields the rows include includes. Include is given at least the
+	// values of the given columns, or of every column when none is given,
+	// the rows it includes are yielded with the values of every column.
+	Scan(ctx context.Context, columns []*Column, include func(RowID, map[uint32][]byte) (bool, error), yield func(RowID, map[uint32][]byte) error) error
+
+	// CreateIndex adds the keys of the rows stored so far to the index,
+	// which is only added to the table once it's created.
+	CreateIndex(x *Index) error
+
+	// ScanIndex yields the identifiers of the rows whose values are within
+	// the bounds of the index, nil bounds are unlimited. B+trees yield them
+	// in the order of the index, hash tables in no particular order.
+	ScanIndex(ctx context.Context, x *Index, lower, upper *IndexBound, yield func(RowID) error) error
+
+	// ReadIndex works like ScanIndex, but yields the rows themselves.
+	ReadIndex(ctx context.Context, x *Index, lower, upper *IndexBound, yield func(RowID, map[uint32][]byte) error) error
+
+	// Verify gives every corruption found in the rows and the indexes.
+	Verify(ctx context.Context) ([]*CorruptionError, error)
+
+	// Vacuum gives back the space that isn't taken by rows anymore.
+	Vacuum(ctx context.Context) (*VacuumStats, error)
+
+	// Reclaimable estimates how many pages a vacuum would give back,
+	// along with how many pages the rows take.
+	Reclaimable() (uint32, uint32, error)
+}
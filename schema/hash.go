@@ -0,0 +1,361 @@
+package schema
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxGlobalDepth is the greatest depth of the directory of a hash index,
+// whose pointers must all fit in the first page. Buckets that are full
+// once the directory can't grow are chained to overflow pages.
+const maxGlobalDepth = 10
+
+// bucket is a bucket of a hash index, stored in a slotted page: slot 0
+// holds its local depth and its overflow page, or page 0 when it has
+// none, the rest hold its keys in no particular order.
+type bucket struct {
+	depth    uint8
+	overflow uint32
+	keys     [][]byte
+}
+
+func decodeBucket(p page) (*bucket, error) {
+	header, err := p.row(0)
+	if err != nil || len(header) != 5 {
+		return nil, errors.New("index page has no bucket header")
+	}
+
+	b := &bucket{depth: header[0], overflow: binary.LittleEndian.Uint32(header[1:])}
+	for slot := 1; slot < p.slots(); slot++ {
+		key, err := p.row(slot)
+		if err != nil {
+			return nil, err
+		}
+
+		b.keys = append(b.keys, append([]byte(nil), key...))
+	}
+
+	return b, nil
+}
+
+// encode writes the bucket to a page, it reports false when it doesn't fit.
+func (b *bucket) encode() (page, bool) {
+	p := newPage()
+
+	header := binary.LittleEndian.AppendUint32([]byte{b.depth}, b.overflow)
+	p.insert(header)
+
+	for _, k := range b.keys {
+		if _, ok := p.insert(k); !ok {
+			return nil, false
+		}
+	}
+
+	return p, true
+}
+
+// hashTable is an extendible hash table stored in a file of the buffer pool.
+// The first page holds the global depth and the directory, whose pointers
+// lead to the buckets by the last bits of the hash of a key, as many as
+// the global depth. Changing the table must be serialized by the caller.
+type hashTable struct {
+	pool *BufferPool
+	file string
+	hash func(key []byte) (uint32, error)
+}
+
+func (h *hashTable) create(tx *tx) error {
+	meta, n, err := h.pool.allocate(h.file)
+	if err != nil {
+		return err
+	}
+	h.pool.unpin(meta)
+
+	if n != btreeMetaPage {
+		return fmt.Errorf("index file '%s' isn't empty", h.file)
+	}
+
+	first, err := h.allocate(tx, &bucket{})
+	if err != nil {
+		return err
+	}
+
+	return h.setDirectory(tx, 0, []uint32{first})
+}
+
+func (h *hashTable) directory() (uint8, []uint32, error) {
+	fr, err := h.pool.pin(h.file, btreeMetaPage)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer h.pool.unpin(fr)
+
+	fr.latch.RLock()
+	defer fr.latch.RUnlock()
+
+	row, err := fr.data.row(0)
+	if err != nil || len(row) < 1 || len(row) != 1+4<<row[0] {
+		return 0, nil, fmt.Errorf("index file '%s' has no directory", h.file)
+	}
+
+	dir := make([]uint32, 1<<row[0])
+	for i := range dir {
+		dir[i] = binary.LittleEndian.Uint32(row[1+4*i:])
+	}
+
+	return row[0], dir, nil
+}
+
+func (h *hashTable) setDirectory(tx *tx, depth uint8, dir []uint32) error {
+	row := []byte{depth}
+	for _, n := range dir {
+		row = binary.LittleEndian.AppendUint32(row, n)
+	}
+
+	meta := newPage()
+	meta.insert(row)
+
+	return tx.writePage(h.file, btreeMetaPage, meta)
+}
+
+func (h *hashTable) read(n uint32) (*bucket, error) {
+	fr, err := h.pool.pin(h.file, n)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.unpin(fr)
+
+	fr.latch.RLock()
+	defer fr.latch.RUnlock()
+
+	return decodeBucket(fr.data)
+}
+
+func (h *hashTable) write(tx *tx, n uint32, b *bucket) error {
+	p, ok := b.encode()
+	if !ok {
+		return fmt.Errorf("bucket of index file '%s' doesn't fit in a page", h.file)
+	}
+
+	return tx.writePage(h.file, n, p)
+}
+
+func (h *hashTable) allocate(tx *tx, b *bucket) (uint32, error) {
+	p, ok := b.encode()
+	if !ok {
+		return 0, fmt.Errorf("bucket of index file '%s' doesn't fit in a page", h.file)
+	}
+
+	return tx.allocatePage(h.file, p)
+}
+
+// insert adds the key to its bucket, a full bucket is split in two by one
+// more bit of the hashes of its keys, doubling the directory when it isn't
+// deep enough. Buckets that can't be split are given an overflow page.
+func (h *hashTable) insert(tx *tx, key []byte) error {
+	if len(key) > MaxIndexKeySize {
+		return fmt.Errorf("index key of %d bytes is too large, keys can have at most %d bytes", len(key), MaxIndexKeySize)
+	}
+
+	hash, err := h.hash(key)
+	if err != nil {
+		return err
+	}
+
+	for {
+		depth, dir, err := h.directory()
+		if err != nil {
+			return err
+		}
+
+		n := dir[hash&(1<<depth-1)]
+		b, err := h.read(n)
+		if err != nil {
+			return err
+		}
+
+		b.keys = append(b.keys, key)
+		if _, ok := b.encode(); ok {
+			return h.write(tx, n, b)
+		}
+		b.keys = b.keys[:len(b.keys)-1]
+
+		// the keys of a bucket with overflow pages are never split
+		// apart, so it keeps every key that was chained to it
+		if b.depth == maxGlobalDepth || b.overflow != btreeMetaPage {
+			return h.overflow(tx, n, key)
+		}
+
+		splittable, err := h.splittable(b, key)
+		if err != nil {
+			return err
+		}
+
+		if !splittable {
+			return h.overflow(tx, n, key)
+		}
+
+		if b.depth == depth {
+			dir = append(dir, dir...)
+			depth++
+		}
+
+		if err := h.split(tx, n, b, depth, dir); err != nil {
+			return err
+		}
+	}
+}
+
+// splittable tells if a bit of the hashes of the keys of the bucket
+// that isn't used yet tells some of them apart, which isn't the case
+// when all of the keys have the same values.
+func (h *hashTable) splittable(b *bucket, key []byte) (bool, error) {
+	first, err := h.hash(key)
+	if err != nil {
+		return false, err
+	}
+
+	for _, k := range b.keys {
+		hash, err := h.hash(k)
+		if err != nil {
+			return false, err
+		}
+
+		if hash != first {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// split moves the keys of the bucket whose next bit is set to a new bucket,
+// the directory pointers to the bucket with that bit set point to the new one.
+func (h *hashTable) split(tx *tx, n uint32, b *bucket, depth uint8, dir []uint32) error {
+	bit := uint32(1) << b.depth
+	b.depth++
+
+	low := &bucket{depth: b.depth}
+	high := &bucket{depth: b.depth}
+	for _, k := range b.keys {
+		hash, err := h.hash(k)
+		if err != nil {
+			return err
+		}
+
+		if hash&bit == 0 {
+			low.keys = append(low.keys, k)
+		} else {
+			high.keys = append(high.keys, k)
+		}
+	}
+
+	m, err := h.allocate(tx, high)
+	if err != nil {
+		return err
+	}
+
+	for i := range dir {
+		if dir[i] == n && uint32(i)&bit != 0 {
+			dir[i] = m
+		}
+	}
+
+	// the directory is written before the bucket loses its keys,
+	// so they're always reachable from it
+	if err := h.setDirectory(tx, depth, dir); err != nil {
+		return err
+	}
+
+	return h.write(tx, n, low)
+}
+
+// overflow adds the key to the first overflow page of the bucket, when it
+// has no room a new page is put first in the chain, so pages that are
+// already full are never gone through again.
+func (h *hashTable) overflow(tx *tx, n uint32, key []byte) error {
+	b, err := h.read(n)
+	if err != nil {
+		return err
+	}
+
+	if b.overflow != btreeMetaPage {
+		first, err := h.read(b.overflow)
+		if err != nil {
+			return err
+		}
+
+		first.keys = append(first.keys, key)
+		if _, ok := first.encode(); ok {
+			return h.write(tx, b.overflow, first)
+		}
+	}
+
+	next, err := h.allocate(tx, &bucket{depth: b.depth, overflow: b.overflow, keys: [][]byte{key}})
+	if err != nil {
+		return err
+	}
+
+	b.overflow = next
+	return h.write(tx, n, b)
+}
+
+// lookup yields the keys of the bucket the hash leads to, along with the
+// ones of its overflow pages. The lookup stops when yield reports false.
+func (h *hashTable) lookup(hash uint32, yield func(key []byte) (bool, error)) error {
+	depth, dir, err := h.directory()
+	if err != nil {
+		return err
+	}
+
+	return h.chain(dir[hash&(1<<depth-1)], yield)
+}
+
+// scan yields the keys of every bucket. The scan stops when yield reports false.
+func (h *hashTable) scan(yield func(key []byte) (bool, error)) error {
+	_, dir, err := h.directory()
+	if err != nil {
+		return err
+	}
+
+	seen := map[uint32]bool{}
+	for _, n := range dir {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		stopped := false
+		err := h.chain(n, func(key []byte) (bool, error) {
+			more, err := yield(key)
+			stopped = !more
+			return more, err
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *hashTable) chain(n uint32, yield func(key []byte) (bool, error)) error {
+	for n != btreeMetaPage {
+		b, err := h.read(n)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range b.keys {
+			more, err := yield(k)
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		n = b.overflow
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jvitoroc/gobase/eval"
+)
+
+func TestHashIndex(t *testing.T) {
+	table := newTestTable(t)
+	fileStorageOf(table).pool = NewBufferPool(8)
+	defer fileStorageOf(table).pool.Close()
+
+	x, err := table.createIndex("test_n", []string{"n"}, HashIndex, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// enough rows for the directory to grow, with a
+	// value repeated until it needs overflow pages
+	const rows = 10000
+	for i := 0; i < rows; i++ {
+		n := strconv.Itoa(i)
+		if i%2 == 0 {
+			n = "-1"
+		}
+
+		if err := table.Insert([]string{n, "x"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	depth, _, err := x.hash.directory()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if depth < 2 {
+		t.Errorf("expected the directory to grow, but its depth is %d", depth)
+	}
+
+	point := func(v any) *IndexBound {
+		return &IndexBound{Values: []any{v}, Inclusive: true}
+	}
+
+	tests := []struct {
+		lower, upper *IndexBound
+		expected     []int64
+	}{
+		{lower: point(int64(4243)), upper: point(int64(4243)), expected: []int64{4243}},
+		{lower: point(int64(4242)), upper: point(int64(4242)), expected: nil},
+		{lower: point(3.0), upper: point(3.0), expected: []int64{3}},
+		{lower: point(eval.NewDecimal(70, 1)), upper: point(eval.NewDecimal(70, 1)), expected: []int64{7}},
+		{lower: point(2.5), upper: point(2.5), expected: nil},
+		{
+			lower:    &IndexBound{Values: []any{int64(10)}},
+			upper:    &IndexBound{Values: []any{int64(15)}, Inclusive: true},
+			expected: []int64{11, 13, 15},
+		},
+	}
+
+	for _, test := range tests {
+		got := scanIndex(t, table, x, test.lower, test.upper)
+		slices.Sort(got)
+		if diff := cmp.Diff(test.expected, got); diff != "" {
+			t.Errorf("scanning from %+v to %+v (-want +got):\n%s", test.lower, test.upper, diff)
+		}
+	}
+
+	if got := scanIndex(t, table, x, point(int64(-1)), point(int64(-1))); len(got) != rows/2 {
+		t.Errorf("expected %d rows with a repeated value, but got %d", rows/2, len(got))
+	}
+
+	if got := scanIndex(t, table, x, nil, nil); len(got) != rows {
+		t.Errorf("expected the index to have %d rows, but got %d", rows, len(got))
+	}
+}
+
+func TestUniqueHashIndex(t *testing.T) {
+	table := newTestTable(t)
+
+	if _, err := table.createIndex("test_s", []string{"s"}, HashIndex, true); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := table.Insert([]string{"1", "a"}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	err := table.Insert([]string{"2", "a"})
+	if err == nil || err.Error() != "duplicate value for unique index 'test_s', (a) already exists" {
+		t.Errorf("expected a duplicate value error, but got %v", err)
+	}
+
+	if _, err := table.createIndex("test_n", []string{"n"}, "gist", false); err == nil || err.Error() != "index method 'gist' does not exist" {
+		t.Errorf("expected an unknown index method to be rejected, but got %v", err)
+	}
+}
@@ -0,0 +1,591 @@
+package schema
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jvitoroc/gobase/eval"
+)
+
+type IndexMethod string
+
+const (
+	// B+trees order the values of an index the same way expressions
+	// compare them, so they can be scanned for equal values or ranges
+	BTreeIndex IndexMethod = "btree"
+
+	// hash tables can only find equal values, but they can
+	// do so without going through the levels of a tree
+	HashIndex IndexMethod = "hash"
+)
+
+// Index holds the values of some of the columns of a table, along with the
+// rows holding them. Its keys are the typed encoding of the values, followed
+// by the identifier of the row holding them.
+type Index struct {
+	Name    string
+	Columns []*Column
+	Method  IndexMethod
+	Unique  bool `json:",omitempty"`
+
+	table *Table
+	tree  *btree
+	hash  *hashTable
+
+	// scans can run along each other, but not along inserts
+	mu sync.RWMutex
+}
+
+// IndexBound limits a scan of an index, its values are compared with
+// the first columns of the index, as many of them as there are values.
+type IndexBound struct {
+	Values    []any
+	Inclusive bool
+}
+
+// openIndex makes the index keep its keys in the file.
+func (s *fileStorage) openIndex(x *Index, file string) {
+	switch x.Method {
+	case BTreeIndex:
+		x.tree = &btree{pool: s.pool, file: file, compare: x.compareKeys}
+	case HashIndex:
+		x.hash = &hashTable{pool: s.pool, file: file, hash: x.hashKey}
+	}
+}
+
+// structure returns the B+tree or the hash table holding the keys.
+func (x *Index) structure() interface {
+	create(tx *tx) error
+	insert(tx *tx, key []byte) error
+} {
+	if x.Method == HashIndex {
+		return x.hash
+	}
+
+	return x.tree
+}
+
+func (s *fileStorage) indexFileName(x *Index) string {
+	return s.fileName() + "." + x.Name + ".index"
+}
+
+// indexable tells if the values of the column can be ordered.
+func indexable(c *Column) bool {
+	if c.IsArray() {
+		return indexable(c.Element())
+	}
+
+	return c.Type != JSONType
+}
+
+// IndexColumns finds the columns an index is made of among the columns
+// of its table, checking that each of them is only indexed once and that
+// their values can be ordered.
+func IndexColumns(table string, columns []*Column, names []string) ([]*Column, error) {
+	indexed := make([]*Column, 0, len(names))
+	for _, n := range names {
+		var c *Column
+		for _, o := range columns {
+			if o.Name == n {
+				c = o
+			}
+		}
+
+		if c == nil {
+			return nil, fmt.Errorf("column '%s' does not exist in table '%s'", n, table)
+		}
+
+		if slices.Contains(indexed, c) {
+			return nil, fmt.Errorf("column '%s' is indexed more than once", n)
+		}
+
+		if !indexable(c) {
+			return nil, fmt.Errorf("column '%s' of type %s can't be indexed", n, c.TypeName())
+		}
+
+		indexed = append(indexed, c)
+	}
+
+	if len(indexed) == 0 {
+		return nil, errors.New("index must have at least one column")
+	}
+
+	return indexed, nil
+}
+
+// key writes the size of the value of each of the
+// indexed columns followed by the value itself.
+func (x *Index) key(blobs map[uint32][]byte) ([]byte, error) {
+	var key []byte
+	for _, c := range x.Columns {
+		key = binary.LittleEndian.AppendUint32(key, uint32(len(blobs[c.ID])))
+		key = append(key, blobs[c.ID]...)
+	}
+
+	if len(key)+rowIDSize > MaxIndexKeySize {
+		return nil, fmt.Errorf("values of index '%s' take %d bytes, but index keys can have at most %d bytes", x.Name, len(key), MaxIndexKeySize-rowIDSize)
+	}
+
+	return key, nil
+}
+
+// decode reads the values of a key, along with the
+// identifier of its row when the key has one.
+func (x *Index) decode(key []byte) ([]any, RowID, error) {
+	values := make([]any, len(x.Columns))
+	for i, c := range x.Columns {
+		if len(key) < 4 || len(key)-4 < int(binary.LittleEndian.Uint32(key)) {
+			return nil, RowID{}, fmt.Errorf("key of index '%s' is corrupted", x.Name)
+		}
+
+		size := binary.LittleEndian.Uint32(key)
+		v, err := blobToGoType(c, key[4:4+size])
+		if err != nil {
+			return nil, RowID{}, err
+		}
+
+		values[i] = v
+		key = key[4+size:]
+	}
+
+	var id RowID
+	if len(key) == rowIDSize {
+		id.Page = binary.LittleEndian.Uint32(key)
+		id.Slot = binary.LittleEndian.Uint16(key[4:])
+	}
+
+	return values, id, nil
+}
+
+// compareKeys orders keys by their values, and keys with
+// the same values by the identifiers of their rows.
+func (x *Index) compareKeys(a, b []byte) (int, error) {
+	av, aid, err := x.decode(a)
+	if err != nil {
+		return 0, err
+	}
+
+	bv, bid, err := x.decode(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if c, err := compareValues(av, bv); c != 0 || err != nil {
+		return c, err
+	}
+
+	if aid.Page != bid.Page {
+		return cmpUint(aid.Page, bid.Page), nil
+	}
+
+	return cmpUint(aid.Slot, bid.Slot), nil
+}
+
+// compareValues compares the values one by one, up to the shortest of them.
+func compareValues(a, b []any) (int, error) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		c, err := eval.Compare(a[i], b[i])
+		if c != 0 || err != nil {
+			return c, err
+		}
+	}
+
+	return 0, nil
+}
+
+// hashKey hashes the values of the key, regardless of its row.
+func (x *Index) hashKey(key []byte) (uint32, error) {
+	values, _, err := x.decode(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return hashValues(values), nil
+}
+
+// hashValues hashes values as they are written in a query, so values
+// that are equal have the same hash even when their encoding differs.
+func hashValues(values []any) uint32 {
+	h := fnv.New32a()
+	for _, v := range values {
+		// -0 is equal to 0
+		if f, ok := v.(float64); ok && f == 0 {
+			v = 0.0
+		}
+
+		h.Write([]byte(FormatValue(v)))
+		h.Write([]byte{0})
+	}
+
+	return h.Sum32()
+}
+
+func cmpUint[T uint16 | uint32](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func (x *Index) insert(tx *tx, key []byte, id RowID) error {
+	key = binary.LittleEndian.AppendUint32(key, id.Page)
+	key = binary.LittleEndian.AppendUint16(key, id.Slot)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.structure().insert(tx, key)
+}
+
+// duplicate checks that no row of a unique index has the values of the key.
+func (x *Index) duplicate(key []byte) error {
+	if !x.Unique {
+		return nil
+	}
+
+	values, _, err := x.decode(key)
+	if err != nil {
+		return err
+	}
+
+	bound := &IndexBound{Values: values, Inclusive: true}
+	found := false
+	err = x.Scan(context.Background(), bound, bound, func(RowID) error {
+		found = true
+		return errStopScan
+	})
+	if err != nil && !errors.Is(err, errStopScan) {
+		return err
+	}
+
+	if !found {
+		return nil
+	}
+
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = FormatValue(v)
+	}
+
+	return fmt.Errorf("duplicate value for unique index '%s', (%s) already exists", x.Name, strings.Join(formatted, ", "))
+}
+
+var errStopScan = errors.New("stop scan")
+
+// Scan yields the identifiers of the rows whose values are within the
+// bounds, nil bounds are unlimited. B+trees yield them in the order of
+// the index, hash tables in no particular order.
+func (x *Index) Scan(ctx context.Context, lower, upper *IndexBound, yield func(RowID) error) error {
+	return x.table.storage.ScanIndex(ctx, x, lower, upper, yield)
+}
+
+func (s *fileStorage) ScanIndex(ctx context.Context, x *Index, lower, upper *IndexBound, yield func(RowID) error) error {
+	s.files.RLock()
+	defer s.files.RUnlock()
+
+	return x.scan(ctx, lower, upper, yield)
+}
+
+func (x *Index) scan(ctx context.Context, lower, upper *IndexBound, yield func(RowID) error) error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.Method == HashIndex {
+		return x.scanHash(ctx, lower, upper, yield)
+	}
+
+	below := func(key []byte) (bool, error) {
+		values, _, err := x.decode(key)
+		if err != nil {
+			return false, err
+		}
+
+		return belowBound(values, lower)
+	}
+
+	return x.tree.scan(below, func(key []byte) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		values, id, err := x.decode(key)
+		if err != nil {
+			return false, err
+		}
+
+		if ok, err := belowBound(values, lower); ok || err != nil {
+			return true, err
+		}
+
+		// keys are ordered, so the ones after it are above the bound too
+		if ok, err := aboveBound(values, upper); ok || err != nil {
+			return false, err
+		}
+
+		return true, yield(id)
+	})
+}
+
+// scanHash looks the values up in the hash table when both bounds hold
+// the same value for each column, otherwise every key has to be checked.
+func (x *Index) scanHash(ctx context.Context, lower, upper *IndexBound, yield func(RowID) error) error {
+	visit := func(key []byte) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		values, id, err := x.decode(key)
+		if err != nil {
+			return false, err
+		}
+
+		if ok, err := belowBound(values, lower); ok || err != nil {
+			return true, err
+		}
+
+		if ok, err := aboveBound(values, upper); ok || err != nil {
+			return true, err
+		}
+
+		return true, yield(id)
+	}
+
+	if values, ok := x.lookupValues(lower, upper); ok {
+		return x.hash.lookup(hashValues(values), visit)
+	}
+
+	return x.hash.scan(visit)
+}
+
+// lookupValues gives the values of the columns the bounds are limited to,
+// as they are stored by the columns, it reports false when the bounds hold
+// a range or a value that the columns would store as a different one, such
+// as 1.5 in an integer column, since it wouldn't have the hash of the keys
+// it's equal to.
+func (x *Index) lookupValues(lower, upper *IndexBound) ([]any, bool) {
+	if lower == nil || upper == nil || !lower.Inclusive || !upper.Inclusive ||
+		len(lower.Values) != len(x.Columns) || len(upper.Values) != len(x.Columns) {
+		return nil, false
+	}
+
+	values := make([]any, len(x.Columns))
+	for i, c := range x.Columns {
+		if eq, err := eval.Compare(lower.Values[i], upper.Values[i]); eq != 0 || err != nil {
+			return nil, false
+		}
+
+		formatted := FormatValue(lower.Values[i])
+		if !checkValueType(c, formatted) {
+			return nil, false
+		}
+
+		blob, err := stringToBlob(c, formatted)
+		if err != nil {
+			return nil, false
+		}
+
+		v, err := blobToGoType(c, blob)
+		if err != nil {
+			return nil, false
+		}
+
+		if eq, err := eval.Compare(v, lower.Values[i]); eq != 0 || err != nil {
+			return nil, false
+		}
+
+		values[i] = v
+	}
+
+	return values, true
+}
+
+func belowBound(values []any, lower *IndexBound) (bool, error) {
+	if lower == nil {
+		return false, nil
+	}
+
+	c, err := compareValues(values, lower.Values)
+	return c < 0 || c == 0 && !lower.Inclusive, err
+}
+
+func aboveBound(values []any, upper *IndexBound) (bool, error) {
+	if upper == nil {
+		return false, nil
+	}
+
+	c, err := compareValues(values, upper.Values)
+	return c > 0 || c == 0 && !upper.Inclusive, err
+}
+
+// GetIndex returns the index of the table with the given name.
+func (t *Table) GetIndex(name string) *Index {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, x := range t.Indexes {
+		if x.Name == name {
+			return x
+		}
+	}
+
+	return nil
+}
+
+// ListIndexes returns the indexes the table has, it can be
+// called while another goroutine creates an index.
+func (t *Table) ListIndexes() []*Index {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.Indexes)
+}
+
+// createIndex indexes the rows the table already has, the index
+// is only added to the table once all of them are in it.
+func (t *Table) createIndex(name string, columns []string, method IndexMethod, unique bool) (*Index, error) {
+	indexed, err := IndexColumns(t.Name, t.Columns, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	if method != BTreeIndex && method != HashIndex {
+		return nil, fmt.Errorf("index method '%s' does not exist", method)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	x := &Index{Name: name, Columns: indexed, Method: method, Unique: unique, table: t}
+	if err := t.storage.CreateIndex(x); err != nil {
+		return nil, err
+	}
+
+	t.Indexes = append(t.Indexes, x)
+
+	return x, nil
+}
+
+func (s *fileStorage) CreateIndex(x *Index) error {
+	if err := s.migrateLegacyFile(); err != nil {
+		return err
+	}
+
+	s.openIndex(x, s.indexFileName(x))
+	if err := s.buildIndex(x); err != nil {
+		s.pool.invalidate(s.indexFileName(x))
+		os.Remove(s.indexFileName(x))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *fileStorage) buildIndex(x *Index) error {
+	// a file left by an index that was never created is replaced
+	if err := os.Remove(s.indexFileName(x)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	tx := s.pool.begin()
+	if err := tx.end(x.structure().create(tx)); err != nil {
+		return err
+	}
+
+	return s.readColumns(context.Background(), x.Columns, func(id RowID, blobs map[uint32][]byte) error {
+		key, err := x.key(blobs)
+		if err != nil {
+			return err
+		}
+
+		if err := x.duplicate(key); err != nil {
+			return fmt.Errorf("couldn't create unique index '%s': %w", x.Name, err)
+		}
+
+		// every row is indexed by a transaction of its
+		// own, so the pages it changes fit in the pool
+		tx := s.pool.begin()
+		return tx.end(x.insert(tx, key, id))
+	})
+}
+
+// indexKeys gives the key of the row for each index of the table,
+// failing when the row would be a duplicate in a unique index.
+func (t *Table) indexKeys(blobs map[uint32][]byte) ([][]byte, error) {
+	if len(t.Indexes) == 0 {
+		return nil, nil
+	}
+
+	keys := make([][]byte, len(t.Indexes))
+	for i, x := range t.Indexes {
+		key, err := x.key(blobs)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := x.duplicate(key); err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	return keys, nil
+}
+
+// ReadIndex works like Read, but only reads the rows whose values
+// are within the bounds of the index, in the order of the index.
+func (t *Table) ReadIndex(ctx context.Context, wr io.Writer, columns []string, x *Index, lower, upper *IndexBound, shouldInclude func(*DeserializedRow) (bool, error)) error {
+	return t.storage.ReadIndex(ctx, x, lower, upper, func(id RowID, blobs map[uint32][]byte) error {
+		r, err := t.deserializeRow(id, blobs)
+		if err != nil {
+			return err
+		}
+
+		return writeRow(wr, r, shouldInclude)
+	})
+}
+
+func (s *fileStorage) ReadIndex(ctx context.Context, x *Index, lower, upper *IndexBound, yield func(RowID, map[uint32][]byte) error) error {
+	s.files.RLock()
+	defer s.files.RUnlock()
+
+	// the rows are only fetched once the scan is
+	// over, so inserts aren't held back by them
+	var ids []RowID
+	err := x.scan(ctx, lower, upper, func(id RowID) error {
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		blobs, err := s.fetch(id)
+		if err != nil {
+			return err
+		}
+
+		if err := yield(id, blobs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
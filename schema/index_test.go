@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func scanIndex(t *testing.T, table *Table, x *Index, lower, upper *IndexBound) []int64 {
+	var got []int64
+	err := x.Scan(context.Background(), lower, upper, func(id RowID) error {
+		r, err := table.Fetch(id)
+		if err != nil {
+			return err
+		}
+
+		got = append(got, r.Values()[0].(int64))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return got
+}
+
+func TestIndex(t *testing.T) {
+	table := newTestTable(t)
+	fileStorageOf(table).pool = NewBufferPool(8)
+	defer fileStorageOf(table).pool.Close()
+
+	// half of the rows are inserted before the index is created
+	const rows = 2000
+	insert := func(from, to int) {
+		for i := from; i < to; i++ {
+			// values are inserted out of order, and every one of them twice
+			n := (i * 7919) % (rows / 2)
+			if err := table.Insert([]string{strconv.Itoa(n), strings.Repeat("x", 20)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	insert(0, rows/2)
+
+	x, err := table.createIndex("test_n", []string{"n"}, BTreeIndex, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	insert(rows/2, rows)
+
+	tests := []struct {
+		lower, upper *IndexBound
+		expected     []int64
+	}{
+		{
+			lower:    &IndexBound{Values: []any{int64(42)}, Inclusive: true},
+			upper:    &IndexBound{Values: []any{int64(42)}, Inclusive: true},
+			expected: []int64{42, 42},
+		},
+		{
+			lower:    &IndexBound{Values: []any{int64(997)}},
+			expected: []int64{998, 998, 999, 999},
+		},
+		{
+			upper:    &IndexBound{Values: []any{int64(1)}, Inclusive: true},
+			expected: []int64{0, 0, 1, 1},
+		},
+		{
+			lower:    &IndexBound{Values: []any{int64(10)}},
+			upper:    &IndexBound{Values: []any{12.5}},
+			expected: []int64{11, 11, 12, 12},
+		},
+		{
+			lower:    &IndexBound{Values: []any{int64(5000)}, Inclusive: true},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		got := scanIndex(t, table, x, test.lower, test.upper)
+		if diff := cmp.Diff(test.expected, got); diff != "" {
+			t.Errorf("scanning from %+v to %+v (-want +got):\n%s", test.lower, test.upper, diff)
+		}
+	}
+
+	if got := scanIndex(t, table, x, nil, nil); len(got) != rows {
+		t.Errorf("expected the index to have %d rows, but got %d", rows, len(got))
+	}
+}
+
+func TestUniqueIndex(t *testing.T) {
+	table := newTestTable(t)
+
+	for _, row := range [][]string{{"1", "a"}, {"2", "a"}, {"2", "b"}} {
+		if err := table.Insert(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := table.createIndex("test_n", []string{"n"}, BTreeIndex, true); err == nil {
+		t.Error("expected duplicated values to prevent the unique index from being created")
+	}
+
+	if len(table.Indexes) != 0 {
+		t.Errorf("expected the index not to be added, but got %d indexes", len(table.Indexes))
+	}
+
+	x, err := table.createIndex("test_s_n", []string{"s", "n"}, BTreeIndex, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = table.Insert([]string{"1", "a"})
+	if err == nil || err.Error() != "duplicate value for unique index 'test_s_n', (a, 1) already exists" {
+		t.Errorf("expected a duplicate value error, but got %v", err)
+	}
+
+	if err := table.Insert([]string{"3", "a"}); err != nil {
+		t.Error(err)
+	}
+
+	if rows := readAll(t, table); len(rows) != 4 {
+		t.Errorf("expected the duplicated row not to be stored, but got %d rows", len(rows))
+	}
+
+	got := scanIndex(t, table, x, &IndexBound{Values: []any{"a"}, Inclusive: true}, &IndexBound{Values: []any{"a"}, Inclusive: true})
+	if diff := cmp.Diff([]int64{1, 2, 3}, got); diff != "" {
+		t.Errorf("scanning a prefix of the index (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	table := newTestTable(t)
+	table.Columns = append(table.Columns, &Column{ID: 3, Name: "j", Type: JSONType})
+
+	tests := []struct {
+		columns  []string
+		expected string
+	}{
+		{columns: []string{"x"}, expected: "column 'x' does not exist in table 'test'"},
+		{columns: []string{"n", "n"}, expected: "column 'n' is indexed more than once"},
+		{columns: []string{"j"}, expected: "column 'j' of type json can't be indexed"},
+	}
+
+	for _, test := range tests {
+		_, err := table.createIndex("test_index", test.columns, BTreeIndex, false)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("expected error '%s', but got %v", test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package schema
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// MemoryEngine keeps every table in memory, so they're gone along with
+// the engine, which makes it fit for tests and ephemeral databases.
+type MemoryEngine struct{}
+
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{}
+}
+
+func (e *MemoryEngine) OpenTable(t *Table) TableStorage {
+	return &memoryStorage{table: t, indexes: map[*Index][][]byte{}}
+}
+
+// Verify finds nothing, since the engine keeps no data apart from its tables.
+func (e *MemoryEngine) Verify(context.Context, []*Table) ([]*CorruptionError, error) {
+	return nil, nil
+}
+
+// memoryStorage keeps the rows of a table in the order they were inserted,
+// identified by their position as if each of them had a page of its own.
+// The keys of every index are kept sorted, whatever the method of the index.
+type memoryStorage struct {
+	table *Table
+
+	// rows are never changed once stored, so readers can
+	// keep going through them after the lock is released
+	mu      sync.RWMutex
+	rows    []map[uint32][]byte
+	indexes map[*Index][][]byte
+}
+
+func (s *memoryStorage) Insert(row map[uint32][]byte, keys [][]byte) (RowID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if uint64(len(s.rows)) > math.MaxUint32 {
+		return RowID{}, fmt.Errorf("table '%s' can't have more than %d rows", s.table.Name, uint64(math.MaxUint32)+1)
+	}
+	id := RowID{Page: uint32(len(s.rows))}
+
+	// every key is placed before any of them is added,
+	// so a row is never stored without being indexed
+	indexed := make([][]byte, len(keys))
+	positions := make([]int, len(keys))
+	for i, x := range s.table.Indexes {
+		indexed[i] = indexKey(keys[i], id)
+
+		n, err := s.position(x, indexed[i])
+		if err != nil {
+			return RowID{}, err
+		}
+		positions[i] = n
+	}
+
+	for i, x := range s.table.Indexes {
+		s.indexes[x] = slices.Insert(s.indexes[x], positions[i], indexed[i])
+	}
+	s.rows = append(s.rows, row)
+
+	return id, nil
+}
+
+// indexKey appends the identifier of the row to the key, as index files do.
+func indexKey(key []byte, id RowID) []byte {
+	key = binary.LittleEndian.AppendUint32(slices.Clip(key), id.Page)
+	return binary.LittleEndian.AppendUint16(key, id.Slot)
+}
+
+// position finds where the key goes among the sorted keys of the index.
+func (s *memoryStorage) position(x *Index, key []byte) (int, error) {
+	var err error
+	n := sort.Search(len(s.indexes[x]), func(i int) bool {
+		c, cerr := x.compareKeys(s.indexes[x][i], key)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+
+		return c >= 0
+	})
+
+	return n, err
+}
+
+func (s *memoryStorage) Fetch(id RowID) (map[uint32][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id.Slot != 0 || int(id.Page) >= len(s.rows) {
+		return nil, fmt.Errorf("row %s does not exist", id)
+	}
+
+	return s.rows[id.Page], nil
+}
+
+// Scan gives include every column of the rows, which are already decoded.
+func (s *memoryStorage) Scan(ctx context.Context, _ []*Column, include func(RowID, map[uint32][]byte) (bool, error), yield func(RowID, map[uint32][]byte) error) error {
+	s.mu.RLock()
+	rows := s.rows
+	s.mu.RUnlock()
+
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		id := RowID{Page: uint32(i)}
+		ok, err := include(id, row)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := yield(id, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStorage) CreateIndex(x *Index) error {
+	s.mu.Lock()
+	rows := s.rows
+	s.indexes[x] = nil
+	s.mu.Unlock()
+
+	// the keys are added one by one, so they're checked
+	// for duplicates among the ones added before them
+	for i, row := range rows {
+		if err := s.indexRow(x, row, RowID{Page: uint32(i)}); err != nil {
+			s.mu.Lock()
+			delete(s.indexes, x)
+			s.mu.Unlock()
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStorage) indexRow(x *Index, row map[uint32][]byte, id RowID) error {
+	key, err := x.key(row)
+	if err != nil {
+		return err
+	}
+
+	if err := x.duplicate(key); err != nil {
+		return fmt.Errorf("couldn't create unique index '%s': %w", x.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key = indexKey(key, id)
+	n, err := s.position(x, key)
+	if err != nil {
+		return err
+	}
+	s.indexes[x] = slices.Insert(s.indexes[x], n, key)
+
+	return nil
+}
+
+// lookup gives the rows whose keys are within the bounds, in their order.
+func (s *memoryStorage) lookup(ctx context.Context, x *Index, lower, upper *IndexBound) ([]RowID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := s.indexes[x]
+
+	var err error
+	n := sort.Search(len(keys), func(i int) bool {
+		values, _, derr := x.decode(keys[i])
+		if derr != nil {
+			err = derr
+			return true
+		}
+
+		below, berr := belowBound(values, lower)
+		if berr != nil {
+			err = berr
+		}
+
+		return !below
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []RowID
+	for _, key := range keys[n:] {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		values, id, err := x.decode(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// keys are ordered, so the ones after it are above the bound too
+		if ok, err := aboveBound(values, upper); ok || err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (s *memoryStorage) ScanIndex(ctx context.Context, x *Index, lower, upper *IndexBound, yield func(RowID) error) error {
+	ids, err := s.lookup(ctx, x, lower, upper)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := yield(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStorage) ReadIndex(ctx context.Context, x *Index, lower, upper *IndexBound, yield func(RowID, map[uint32][]byte) error) error {
+	return s.ScanIndex(ctx, x, lower, upper, func(id RowID) error {
+		row, err := s.Fetch(id)
+		if err != nil {
+			return err
+		}
+
+		return yield(id, row)
+	})
+}
+
+// Verify finds nothing, rows and keys can't be corrupted while in memory.
+func (s *memoryStorage) Verify(context.Context) ([]*CorruptionError, error) {
+	return nil, nil
+}
+
+// Vacuum has nothing to give back, rows take no more memory than they need.
+func (s *memoryStorage) Vacuum(context.Context) (*VacuumStats, error) {
+	return &VacuumStats{Table: s.table.Name}, nil
+}
+
+func (s *memoryStorage) Reclaimable() (uint32, uint32, error) {
+	return 0, 0, nil
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newMemoryTable(t *testing.T) *Table {
+	s := NewSchemaWithEngine(NewMemoryEngine())
+
+	table, err := s.CreateTable("test", []*NewColumn{{Name: "n", Type: Int64Type}, {Name: "s", Type: StringType}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return table
+}
+
+func TestMemoryEngine(t *testing.T) {
+	table := newMemoryTable(t)
+
+	// half of the rows are inserted before the indexes are created
+	const rows = 200
+	insert := func(from, to int) {
+		for i := from; i < to; i++ {
+			n := (i * 7919) % (rows / 2)
+			if err := table.Insert([]string{strconv.Itoa(n), "row " + strconv.Itoa(i)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	insert(0, rows/2)
+
+	tree, err := table.createIndex("test_n", []string{"n"}, BTreeIndex, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := table.createIndex("test_s", []string{"s"}, HashIndex, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insert(rows/2, rows)
+
+	if got := readAll(t, table); len(got) != rows {
+		t.Errorf("expected %d rows, but got %d", rows, len(got))
+	}
+
+	tests := []struct {
+		x            *Index
+		lower, upper *IndexBound
+		expected     []int64
+	}{
+		{
+			x:        tree,
+			lower:    &IndexBound{Values: []any{int64(42)}, Inclusive: true},
+			upper:    &IndexBound{Values: []any{int64(42)}, Inclusive: true},
+			expected: []int64{42, 42},
+		},
+		{
+			x:        tree,
+			lower:    &IndexBound{Values: []any{int64(10)}},
+			upper:    &IndexBound{Values: []any{12.5}},
+			expected: []int64{11, 11, 12, 12},
+		},
+		{
+			x:        tree,
+			lower:    &IndexBound{Values: []any{int64(97)}},
+			expected: []int64{98, 98, 99, 99},
+		},
+		{
+			x:        hash,
+			lower:    &IndexBound{Values: []any{"row 150"}, Inclusive: true},
+			upper:    &IndexBound{Values: []any{"row 150"}, Inclusive: true},
+			expected: []int64{(150 * 7919) % (rows / 2)},
+		},
+	}
+
+	for _, test := range tests {
+		got := scanIndex(t, table, test.x, test.lower, test.upper)
+		if diff := cmp.Diff(test.expected, got); diff != "" {
+			t.Errorf("scanning %s from %+v to %+v (-want +got):\n%s", test.x.Name, test.lower, test.upper, diff)
+		}
+	}
+
+	err = table.Insert([]string{"1", "row 7"})
+	if err == nil || err.Error() != "duplicate value for unique index 'test_s', (row 7) already exists" {
+		t.Errorf("expected a duplicate value error, but got %v", err)
+	}
+
+	if got := scanIndex(t, table, tree, nil, nil); len(got) != rows {
+		t.Errorf("expected the duplicated row not to be indexed, but the index has %d rows", len(got))
+	}
+
+	if _, err := table.createIndex("test_n_unique", []string{"n"}, BTreeIndex, true); err == nil {
+		t.Error("expected duplicated values to prevent the unique index from being created")
+	}
+
+	if _, err := table.Fetch(RowID{Page: rows}); err == nil {
+		t.Error("expected fetching a missing row to fail")
+	}
+
+	corruptions, err := table.Verify(context.Background())
+	if err != nil || len(corruptions) != 0 {
+		t.Errorf("expected no corruption, but got %v, %v", corruptions, err)
+	}
+}
@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+const (
+	// PageSize is the size of every page of a table file,
+	// a row must fit in a single page along with its slot.
+	PageSize = 8192
+
+	pageHeaderSize = 12
+	slotSize       = 4
+
+	// MaxRowSize is the size of the largest row that fits in an empty page.
+	MaxRowSize = PageSize - pageHeaderSize - slotSize
+)
+
+// pageMagic starts every page, table files that don't start with
+// it or with pageMagicV1 are in the legacy format.
+var pageMagic = [4]byte{'G', 'B', 'P', '2'}
+
+// pageMagicV1 starts the pages written before they had checksums, whose
+// header has no room for one, so they're read without checking it. They
+// take the current format once written back, if they have room for it.
+var pageMagicV1 = [4]byte{'G', 'B', 'P', 'G'}
+
+const pageHeaderSizeV1 = 8
+
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// RowID locates a row by the page it is in and its slot in the page.
+type RowID struct {
+	Page uint32
+	Slot uint16
+}
+
+func (id RowID) String() string {
+	return fmt.Sprintf("(%d,%d)", id.Page, id.Slot)
+}
+
+// page is a slotted page, the slot directory grows forward right after the
+// header and rows grow backward from the end of the page, the free space is
+// whatever is left in between:
+//
+//	magic | slots | rows start | checksum | slot 0 ... slot n | free space | row n ... row 0
+//
+// each slot holds the offset and the size of its row, so rows can be
+// moved around the page without changing their identifiers. The checksum
+// is the CRC32C of the rest of the page, it's set when the page is written.
+type page []byte
+
+func newPage() page {
+	p := make(page, PageSize)
+	copy(p, pageMagic[:])
+	p.setSlots(0)
+	p.setRowsStart(PageSize)
+
+	return p
+}
+
+func (p page) valid() bool {
+	return len(p) == PageSize && ([4]byte(p[:4]) == pageMagic || p.isV1()) &&
+		p.headerSize()+p.slots()*slotSize <= p.rowsStart() && p.rowsStart() <= PageSize
+}
+
+func (p page) isV1() bool {
+	return [4]byte(p[:4]) == pageMagicV1
+}
+
+func (p page) headerSize() int {
+	if p.isV1() {
+		return pageHeaderSizeV1
+	}
+
+	return pageHeaderSize
+}
+
+func (p page) slots() int {
+	return int(binary.LittleEndian.Uint16(p[4:]))
+}
+
+func (p page) setSlots(n int) {
+	binary.LittleEndian.PutUint16(p[4:], uint16(n))
+}
+
+// rowsStart is the offset of the last row written to the page.
+func (p page) rowsStart() int {
+	return int(binary.LittleEndian.Uint16(p[6:]))
+}
+
+func (p page) setRowsStart(offset int) {
+	binary.LittleEndian.PutUint16(p[6:], uint16(offset))
+}
+
+// checksum computes the CRC32C of the page, leaving out the one it stores.
+func (p page) checksum() uint32 {
+	crc := crc32.Update(0, checksumTable, p[:8])
+	return crc32.Update(crc, checksumTable, p[pageHeaderSize:])
+}
+
+func (p page) storedChecksum() uint32 {
+	return binary.LittleEndian.Uint32(p[8:])
+}
+
+// withChecksum gives a copy of the page storing its checksum, pages in the
+// earlier format are upgraded first, the ones that can't be stay as they are.
+func (p page) withChecksum() page {
+	c := page(bytes.Clone(p))
+	if c.isV1() && !c.upgrade() {
+		return c
+	}
+	binary.LittleEndian.PutUint32(c[8:], c.checksum())
+
+	return c
+}
+
+// upgrade moves the slots forward to make room for the checksum in the
+// header, it reports false when the free space can't take them.
+func (p page) upgrade() bool {
+	if p.freeSpace() < pageHeaderSize-pageHeaderSizeV1 {
+		return false
+	}
+
+	copy(p[pageHeaderSize:], p[pageHeaderSizeV1:pageHeaderSizeV1+p.slots()*slotSize])
+	copy(p, pageMagic[:])
+
+	return true
+}
+
+func (p page) freeSpace() int {
+	return p.rowsStart() - p.headerSize() - p.slots()*slotSize
+}
+
+// insert copies the row to the page and gives its slot,
+// it reports false when there isn't enough free space.
+func (p page) insert(row []byte) (uint16, bool) {
+	if len(row)+slotSize > p.freeSpace() {
+		return 0, false
+	}
+
+	slot := p.slots()
+	offset := p.rowsStart() - len(row)
+	copy(p[offset:], row)
+
+	s := p[p.headerSize()+slot*slotSize:]
+	binary.LittleEndian.PutUint16(s, uint16(offset))
+	binary.LittleEndian.PutUint16(s[2:], uint16(len(row)))
+
+	p.setSlots(slot + 1)
+	p.setRowsStart(offset)
+
+	return uint16(slot), true
+}
+
+// row returns the row stored in the slot, which is part of the page.
+func (p page) row(slot int) ([]byte, error) {
+	if slot >= p.slots() {
+		return nil, fmt.Errorf("page has no slot %d", slot)
+	}
+
+	s := p[p.headerSize()+slot*slotSize:]
+	offset := int(binary.LittleEndian.Uint16(s))
+	size := int(binary.LittleEndian.Uint16(s[2:]))
+	if offset < p.rowsStart() || offset+size > PageSize {
+		return nil, fmt.Errorf("slot %d points outside of the page", slot)
+	}
+
+	return p[offset : offset+size], nil
+}
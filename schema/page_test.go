@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPageInsert(t *testing.T) {
+	p := newPage()
+	if !p.valid() || p.freeSpace() != PageSize-pageHeaderSize {
+		t.Errorf("expected an empty valid page, but got %d bytes free", p.freeSpace())
+		return
+	}
+
+	row := bytes.Repeat([]byte{7}, 1000)
+	n := 0
+	for {
+		row[0] = byte(n)
+		slot, ok := p.insert(row)
+		if !ok {
+			break
+		}
+
+		if int(slot) != n {
+			t.Errorf("expected slot %d, but got %d", n, slot)
+			return
+		}
+		n++
+	}
+
+	if want := (PageSize - pageHeaderSize) / (1000 + slotSize); n != want {
+		t.Errorf("expected %d rows to fit in a page, but got %d", want, n)
+	}
+
+	for slot := 0; slot < n; slot++ {
+		got, err := p.row(slot)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(got) != 1000 || got[0] != byte(slot) {
+			t.Errorf("slot %d holds the wrong row", slot)
+		}
+	}
+
+	if _, err := p.row(n); err == nil {
+		t.Errorf("expected slot %d not to exist", n)
+	}
+
+	if !p.valid() {
+		t.Error("expected a full page to be valid")
+	}
+
+	if _, ok := newPage().insert(make([]byte, MaxRowSize+1)); ok {
+		t.Error("expected a row larger than MaxRowSize not to fit")
+	}
+}
+
+func TestPageV1(t *testing.T) {
+	// pages written before they had checksums
+	v1 := func(rows int, size int) page {
+		p := make(page, PageSize)
+		copy(p, pageMagicV1[:])
+		p.setSlots(0)
+		p.setRowsStart(PageSize)
+		for i := 0; i < rows; i++ {
+			if _, ok := p.insert(bytes.Repeat([]byte{byte(i)}, size)); !ok {
+				t.Fatalf("row %d doesn't fit", i)
+			}
+		}
+
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		p        page
+		upgraded bool
+	}{
+		{name: "with room", p: v1(3, 100), upgraded: true},
+		{name: "full", p: v1(2, (PageSize-pageHeaderSizeV1)/2-slotSize), upgraded: false},
+	}
+
+	for _, test := range tests {
+		file, err := os.Create(filepath.Join(t.TempDir(), "table"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		if _, err := file.WriteAt(test.p, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := readPage(file, 0); err != nil {
+			t.Errorf("%s: expected the page to be read without a checksum, but got %v", test.name, err)
+			continue
+		}
+
+		if err := writePage(file, 0, test.p); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := readPage(file, 0)
+		if err != nil {
+			t.Errorf("%s: expected the page written back to be read, but got %v", test.name, err)
+			continue
+		}
+
+		if upgraded := !got.isV1(); upgraded != test.upgraded {
+			t.Errorf("%s: expected upgraded to be %t, but got %t", test.name, test.upgraded, upgraded)
+		}
+
+		for slot := 0; slot < test.p.slots(); slot++ {
+			want, _ := test.p.row(slot)
+			row, err := got.row(slot)
+			if err != nil || !bytes.Equal(want, row) {
+				t.Errorf("%s: slot %d changed, %v", test.name, slot, err)
+			}
+		}
+	}
+}
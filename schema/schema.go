@@ -1,29 +1,76 @@
 package schema
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/jvitoroc/gobase/eval"
 )
 
 type Schema struct {
 	mu     sync.Mutex
 	tables []*Table
+	types  []*eval.EnumType
 
-	rootDir string
+	engine StorageEngine
 }
 
 func NewSchema(rootDir string) *Schema {
-	return &Schema{rootDir: rootDir}
+	return NewSchemaWithBufferPool(rootDir, NewBufferPool(DefaultBufferPoolPages))
+}
+
+// NewSchemaWithBufferPool creates a schema whose tables cache their pages in
+// the given pool, which can be shared with other schemas.
+func NewSchemaWithBufferPool(rootDir string, pool *BufferPool) *Schema {
+	return NewSchemaWithEngine(NewFileEngine(rootDir, pool))
+}
+
+// NewSchemaWithEngine creates a schema whose tables are kept by the engine.
+func NewSchemaWithEngine(engine StorageEngine) *Schema {
+	return &Schema{engine: engine}
+}
+
+// BufferPool returns the pool caching the pages of the schema's tables,
+// which is nil when they aren't kept in files.
+func (s *Schema) BufferPool() *BufferPool {
+	if e, ok := s.engine.(*FileEngine); ok {
+		return e.BufferPool()
+	}
+
+	return nil
 }
 
 type NewColumn struct {
 	Name string
 	Type ColumnDataType
+
+	Precision int
+	Scale     int
+	Length    int
+
+	// name of the type of enum columns
+	Enum string
 }
 
 func (s *Schema) CreateTable(name string, columns []*NewColumn) (*Table, error) {
+	return s.CreateTableWithOptions(name, columns, TableOptions{})
+}
+
+// CreateTableWithOptions creates a table with the given settings.
+func (s *Schema) CreateTableWithOptions(name string, columns []*NewColumn, options TableOptions) (*Table, error) {
+	if err := CheckCompression(options.Compression); err != nil {
+		return nil, err
+	}
+
+	if err := CheckLayout(options.Layout); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -37,6 +84,18 @@ func (s *Schema) CreateTable(name string, columns []*NewColumn) (*Table, error)
 			ID:   uuid.New().ID(),
 			Name: columns[i].Name,
 			Type: columns[i].Type,
+
+			Precision: columns[i].Precision,
+			Scale:     columns[i].Scale,
+			Length:    columns[i].Length,
+		}
+
+		if columns[i].Enum != "" {
+			c[i].Enum = s.getType(columns[i].Enum)
+		}
+
+		if err := c[i].CheckDefinition(); err != nil {
+			return nil, err
 		}
 	}
 
@@ -45,8 +104,10 @@ func (s *Schema) CreateTable(name string, columns []*NewColumn) (*Table, error)
 		Name:    name,
 		Columns: c,
 
-		rootDir: s.rootDir,
+		Compression: options.Compression,
+		Layout:      options.Layout,
 	}
+	t.storage = s.engine.OpenTable(t)
 
 	s.tables = append(s.tables, t)
 
@@ -63,6 +124,41 @@ func (s *Schema) GetTable(name string) *Table {
 	return nil
 }
 
+// CreateIndex indexes the columns of the table, index names
+// are shared by all the tables of the schema.
+func (s *Schema) CreateIndex(name string, table string, columns []string, method IndexMethod, unique bool) (*Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.getIndex(name) != nil {
+		return nil, fmt.Errorf("index with name '%s' already exists", name)
+	}
+
+	t := s.GetTable(table)
+	if t == nil {
+		return nil, fmt.Errorf("table with name '%s' does not exist", table)
+	}
+
+	return t.createIndex(name, columns, method, unique)
+}
+
+func (s *Schema) GetIndex(name string) *Index {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getIndex(name)
+}
+
+func (s *Schema) getIndex(name string) *Index {
+	for _, t := range s.tables {
+		if x := t.GetIndex(name); x != nil {
+			return x
+		}
+	}
+
+	return nil
+}
+
 func (s *Schema) hasTable(name string) bool {
 	for _, t := range s.tables {
 		if t.Name == name {
@@ -72,3 +168,100 @@ func (s *Schema) hasTable(name string) bool {
 
 	return false
 }
+
+// CreateType creates an enumerated type, which columns can be declared with.
+func (s *Schema) CreateType(name string, labels []string) (*eval.EnumType, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.getType(name) != nil {
+		return nil, fmt.Errorf("type with name '%s' already exists", name)
+	}
+
+	if err := CheckEnumLabels(labels); err != nil {
+		return nil, err
+	}
+
+	t := &eval.EnumType{Name: name, Labels: slices.Clone(labels)}
+	s.types = append(s.types, t)
+
+	return t, nil
+}
+
+// storedSchema is what is kept of a schema when it's written as json.
+type storedSchema struct {
+	Types []*eval.EnumType `json:",omitempty"`
+}
+
+// MarshalJSON writes the types created in the schema.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return json.Marshal(storedSchema{Types: s.types})
+}
+
+// UnmarshalJSON reads the types written by MarshalJSON into the schema.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var stored storedSchema
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range stored.Types {
+		if s.getType(t.Name) != nil {
+			return fmt.Errorf("type with name '%s' already exists", t.Name)
+		}
+
+		if err := CheckEnumLabels(t.Labels); err != nil {
+			return fmt.Errorf("type '%s': %w", t.Name, err)
+		}
+
+		s.types = append(s.types, t)
+	}
+
+	return nil
+}
+
+func (s *Schema) GetType(name string) *eval.EnumType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getType(name)
+}
+
+func (s *Schema) getType(name string) *eval.EnumType {
+	for _, t := range s.types {
+		if t.Name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// CheckEnumLabels checks that there is at least one label, that
+// none of them repeats and that their positions fit in the two
+// bytes enum values are stored in.
+func CheckEnumLabels(labels []string) error {
+	if len(labels) == 0 {
+		return errors.New("enum must have at least one label")
+	}
+
+	if len(labels) > math.MaxUint16+1 {
+		return fmt.Errorf("enum can't have more than %d labels, but got %d", math.MaxUint16+1, len(labels))
+	}
+
+	seen := map[string]bool{}
+	for _, l := range labels {
+		if seen[l] {
+			return fmt.Errorf("label '%s' is declared more than once", l)
+		}
+		seen[l] = true
+	}
+
+	return nil
+}
@@ -1,14 +1,17 @@
 package schema
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/jvitoroc/gobase/eval"
+
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestCreateTableHappyPath(t *testing.T) {
-	s := &Schema{}
+	s := NewSchemaWithEngine(NewMemoryEngine())
 
 	has := s.hasTable("test")
 	if has {
@@ -77,3 +80,90 @@ func TestCreateTableHappyPath(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateType(t *testing.T) {
+	s := NewSchemaWithEngine(NewMemoryEngine())
+
+	mood, err := s.CreateType("mood", []string{"sad", "ok", "happy"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if s.GetType("mood") != mood {
+		t.Error("expected to get the created type")
+		return
+	}
+
+	for _, tt := range []struct {
+		name    string
+		labels  []string
+		wantErr string
+	}{
+		{name: "mood", labels: []string{"a"}, wantErr: "type with name 'mood' already exists"},
+		{name: "empty", wantErr: "enum must have at least one label"},
+		{name: "twice", labels: []string{"a", "b", "a"}, wantErr: "label 'a' is declared more than once"},
+	} {
+		_, err := s.CreateType(tt.name, tt.labels)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("expected error '%s', but got %v", tt.wantErr, err)
+		}
+	}
+
+	table, err := s.CreateTable("people", []*NewColumn{{Name: "m", Type: EnumType, Enum: "mood"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	c := table.Columns[0]
+	if c.Enum != mood || c.TypeName() != "mood" {
+		t.Errorf("expected the column to be of type mood, but got %s", c.TypeName())
+		return
+	}
+
+	blob, err := stringToBlob(c, "happy")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got, err := blobToGoType(c, blob)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if v, ok := got.(eval.Enum); !ok || v.String() != "happy" || v.Index() != 2 || len(blob) != 2 {
+		t.Errorf("expected 'happy' stored as its position, but got %v from %x", got, blob)
+	}
+
+	if err := c.CheckValue("angry"); err == nil {
+		t.Error("expected a value that isn't a label to be invalid")
+	}
+
+	_, err = s.CreateTable("others", []*NewColumn{{Name: "m", Type: EnumType, Enum: "feeling"}})
+	if want := "type of enum column 'm' is not defined"; err == nil || err.Error() != want {
+		t.Errorf("expected error '%s', but got %v", want, err)
+	}
+
+	stored, err := json.Marshal(s)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	read := NewSchemaWithEngine(NewMemoryEngine())
+	if err := json.Unmarshal(stored, read); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if diff := cmp.Diff(mood, read.GetType("mood")); diff != "" {
+		t.Errorf("expected the type to be read back as it was created (-want +got):\n%s", diff)
+	}
+
+	if err := json.Unmarshal(stored, read); err == nil || err.Error() != "type with name 'mood' already exists" {
+		t.Errorf("expected a type read twice to be rejected, but got %v", err)
+	}
+}
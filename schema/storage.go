@@ -0,0 +1,533 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// storedRow is a row as it is read from a page of the table file.
+type storedRow struct {
+	id   RowID
+	data []byte
+}
+
+// serializeRow writes the id and the size of each column followed by its value.
+func (t *Table) serializeRow(blobs map[uint32][]byte) ([]byte, error) {
+	var row []byte
+	for _, c := range t.Columns {
+		row = binary.LittleEndian.AppendUint32(row, c.ID)                     // write column id
+		row = binary.LittleEndian.AppendUint32(row, uint32(len(blobs[c.ID]))) // write column size
+		row = append(row, blobs[c.ID]...)
+	}
+
+	return row, nil
+}
+
+// FileEngine keeps every table in a file of the root directory named by the
+// identifier of the table, and every index in a file named after the table
+// file, caching their pages in a buffer pool.
+type FileEngine struct {
+	rootDir string
+	pool    *BufferPool
+}
+
+// NewFileEngine creates an engine whose tables cache their pages in the
+// given pool, which can be shared with other engines.
+func NewFileEngine(rootDir string, pool *BufferPool) *FileEngine {
+	return &FileEngine{rootDir: rootDir, pool: pool}
+}
+
+// BufferPool returns the pool caching the pages of the engine's tables.
+func (e *FileEngine) BufferPool() *BufferPool {
+	return e.pool
+}
+
+func (e *FileEngine) OpenTable(t *Table) TableStorage {
+	return &fileStorage{table: t, rootDir: e.rootDir, pool: e.pool}
+}
+
+// fileStorage keeps the rows of a table in the pages of its file.
+type fileStorage struct {
+	table   *Table
+	rootDir string
+	pool    *BufferPool
+
+	// held for reading while the files of the table are read,
+	// and for writing while a vacuum replaces them
+	files sync.RWMutex
+
+	// set once the table file is known to have pages, so it's only
+	// checked for the legacy format until it's first written to
+	migrated atomic.Bool
+}
+
+func (s *fileStorage) fileName() string {
+	return path.Join(s.rootDir, strconv.FormatUint(uint64(s.table.ID), 10))
+}
+
+func (s *fileStorage) Insert(row map[uint32][]byte, keys [][]byte) (RowID, error) {
+	// the row and its keys are written by the same transaction,
+	// so a row is never stored without being indexed
+	tx := s.pool.begin()
+	id, err := s.insert(tx, row, keys)
+
+	return id, tx.end(err)
+}
+
+func (s *fileStorage) insert(tx *tx, row map[uint32][]byte, keys [][]byte) (RowID, error) {
+	id, err := s.write(tx, row)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	for i, x := range s.table.Indexes {
+		if err := x.insert(tx, keys[i], id); err != nil {
+			return RowID{}, err
+		}
+	}
+
+	return id, nil
+}
+
+// write stores the row in the last page of the table file, or in
+// a new page when it doesn't fit there, rows of compressed tables
+// only have to fit in a page once compressed.
+func (s *fileStorage) write(tx *tx, blobs map[uint32][]byte) (RowID, error) {
+	if s.table.Layout == ColumnarLayout {
+		return s.writeColumnar(tx, blobs)
+	}
+
+	row, err := s.table.serializeRow(blobs)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	if s.table.Compression == NoCompression && len(row) > MaxRowSize {
+		return RowID{}, fmt.Errorf("row of %d bytes doesn't fit in a page, rows can have at most %d bytes", len(row), MaxRowSize)
+	}
+
+	if err := s.migrateLegacyFile(); err != nil {
+		return RowID{}, err
+	}
+
+	pages, err := s.pool.pageCount(s.fileName())
+	if err != nil {
+		return RowID{}, err
+	}
+
+	if pages > 0 {
+		fr, err := s.pool.pin(s.fileName(), pages-1)
+		if err != nil {
+			return RowID{}, err
+		}
+
+		fr.latch.Lock()
+		slot, ok, err := s.table.appendRow(fr.data, row)
+		fr.latch.Unlock()
+
+		if err != nil {
+			s.pool.unpin(fr)
+			return RowID{}, fmt.Errorf("page %d: %w", pages-1, err)
+		}
+
+		if ok {
+			tx.dirty(fr)
+			return RowID{Page: pages - 1, Slot: slot}, nil
+		}
+		s.pool.unpin(fr)
+	}
+
+	p := newPage()
+	slot, ok, err := s.table.appendRow(p, row)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	if !ok {
+		return RowID{}, fmt.Errorf("row of %d bytes doesn't fit in a page once compressed", len(row))
+	}
+
+	n, err := tx.allocatePage(s.fileName(), p)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	return RowID{Page: n, Slot: slot}, nil
+}
+
+func (s *fileStorage) Fetch(id RowID) (map[uint32][]byte, error) {
+	s.files.RLock()
+	defer s.files.RUnlock()
+
+	return s.fetch(id)
+}
+
+func (s *fileStorage) fetch(id RowID) (map[uint32][]byte, error) {
+	fr, err := s.pool.pin(s.fileName(), id.Page)
+	if err != nil {
+		return nil, s.corrupted(err)
+	}
+	defer s.pool.unpin(fr)
+
+	fr.latch.RLock()
+	defer fr.latch.RUnlock()
+
+	if s.table.Layout == ColumnarLayout {
+		return s.fetchColumnar(fr.data, id)
+	}
+
+	rows, err := s.table.pageRows(fr.data)
+	if err != nil {
+		return nil, fmt.Errorf("row %s: %w", id, err)
+	}
+
+	if int(id.Slot) >= len(rows) {
+		return nil, fmt.Errorf("row %s: page has no slot %d", id, id.Slot)
+	}
+
+	return s.table.storedColumns(rows[id.Slot])
+}
+
+// Scan reads the rows of the table file, rows of legacy
+// files are yielded without identifier, since they have no pages.
+func (s *fileStorage) Scan(ctx context.Context, columns []*Column, include func(RowID, map[uint32][]byte) (bool, error), yield func(RowID, map[uint32][]byte) error) error {
+	s.files.RLock()
+	defer s.files.RUnlock()
+
+	legacy, err := s.isLegacy()
+	if err != nil {
+		return err
+	}
+
+	visit := func(id RowID, blobs map[uint32][]byte) error {
+		ok, err := include(id, blobs)
+		if err != nil || !ok {
+			return err
+		}
+
+		return yield(id, blobs)
+	}
+
+	if legacy {
+		// rows are yielded one by one, so the first error
+		// is kept until they've all been read
+		var visitErr error
+		err := s.readLegacyFile(ctx, func(data []byte) {
+			if visitErr != nil {
+				return
+			}
+
+			blobs, err := s.table.storedColumns(data)
+			if err != nil {
+				visitErr = err
+				return
+			}
+
+			visitErr = visit(RowID{}, blobs)
+		})
+		if err != nil {
+			return err
+		}
+
+		return visitErr
+	}
+
+	if s.table.Layout == ColumnarLayout {
+		return s.scanColumnar(ctx, columns, include, yield)
+	}
+
+	return s.readColumns(ctx, columns, visit)
+}
+
+func (s *fileStorage) isLegacy() (bool, error) {
+	if s.migrated.Load() {
+		return false, nil
+	}
+
+	file, err := os.Open(s.fileName())
+	if errors.Is(err, os.ErrNotExist) {
+		// tables are created without rows
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	return isLegacyFile(file)
+}
+
+func pageCount(file *os.File) (uint32, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	pages := uint32(info.Size() / PageSize)
+	if rest := info.Size() % PageSize; rest != 0 {
+		return 0, &CorruptionError{
+			File:   file.Name(),
+			Offset: int64(pages) * PageSize,
+			Reason: fmt.Sprintf("page %d is incomplete, it has %d bytes", pages, rest),
+		}
+	}
+
+	return pages, nil
+}
+
+func readPage(file *os.File, n uint32) (page, error) {
+	p := make(page, PageSize)
+	if _, err := file.ReadAt(p, int64(n)*PageSize); err != nil {
+		return nil, fmt.Errorf("couldn't read page %d: %w", n, err)
+	}
+
+	if err := checkPage(file.Name(), n, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// checkPage makes sure the page read from the file is the one that was written.
+func checkPage(file string, n uint32, p page) error {
+	if stored, computed := p.storedChecksum(), p.checksum(); !p.isV1() && stored != computed {
+		return &CorruptionError{
+			File:   file,
+			Offset: int64(n) * PageSize,
+			Reason: fmt.Sprintf("page %d has checksum %08x, but %08x was stored", n, computed, stored),
+		}
+	}
+
+	if !p.valid() {
+		return &CorruptionError{File: file, Offset: int64(n) * PageSize, Reason: fmt.Sprintf("page %d is malformed", n)}
+	}
+
+	return nil
+}
+
+func writePage(file *os.File, n uint32, p page) error {
+	if _, err := file.WriteAt(p.withChecksum(), int64(n)*PageSize); err != nil {
+		return fmt.Errorf("an error occurred writing page %d to disk: %w", n, err)
+	}
+
+	return nil
+}
+
+// isLegacyFile tells if the file holds size prefixed rows
+// instead of pages, which is how tables used to be stored.
+func isLegacyFile(file *os.File) (bool, error) {
+	magic := make([]byte, len(pageMagic))
+	_, err := file.ReadAt(magic, 0)
+	if errors.Is(err, io.EOF) {
+		// empty files are written with pages
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return [4]byte(magic) != pageMagic && [4]byte(magic) != pageMagicV1, nil
+}
+
+// readPages reads the rows of every page through the buffer pool,
+// the rows are copied, since the pages can change once unpinned.
+func (s *fileStorage) readPages(ctx context.Context, yield func(storedRow) error) error {
+	pages, err := s.pool.pageCount(s.fileName())
+	if err != nil {
+		return s.corrupted(err)
+	}
+
+	for n := uint32(0); n < pages; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fr, err := s.pool.pin(s.fileName(), n)
+		if err != nil {
+			return s.corrupted(err)
+		}
+
+		fr.latch.RLock()
+		data, err := s.table.pageRows(fr.data)
+		rows := make([]storedRow, len(data))
+		for slot, row := range data {
+			rows[slot] = storedRow{id: RowID{Page: n, Slot: uint16(slot)}, data: bytes.Clone(row)}
+		}
+		fr.latch.RUnlock()
+		s.pool.unpin(fr)
+
+		if err != nil {
+			return fmt.Errorf("page %d: %w", n, err)
+		}
+
+		for _, r := range rows {
+			if err := yield(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *fileStorage) readLegacyFile(ctx context.Context, yield func([]byte)) error {
+	file, err := os.Open(s.fileName())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = readLegacyRows(ctx, file, func(row []byte, _ int64) {
+		yield(row)
+	})
+
+	return s.corrupted(err)
+}
+
+// readLegacyRows reads files made of rows prefixed by their size, yielding
+// each row along with its offset. A size that goes past the end of the file
+// is reported as a corruption, since the rows after it can't be found.
+func readLegacyRows(ctx context.Context, file *os.File, yield func(row []byte, offset int64)) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(file)
+	offset := int64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if offset == info.Size() {
+			return nil
+		}
+
+		rowSizeBytes := make([]byte, 4)
+		if info.Size()-offset < int64(len(rowSizeBytes)) {
+			return &CorruptionError{File: file.Name(), Offset: offset, Reason: "size of row is incomplete"}
+		}
+
+		if _, err := io.ReadFull(r, rowSizeBytes); err != nil {
+			return err
+		}
+
+		size := int64(binary.LittleEndian.Uint32(rowSizeBytes))
+		if offset+4+size > info.Size() {
+			return &CorruptionError{
+				File:   file.Name(),
+				Offset: offset,
+				Reason: fmt.Sprintf("row of %d bytes goes past the end of the file", size),
+			}
+		}
+
+		row := make([]byte, size)
+		if _, err := io.ReadFull(r, row); err != nil {
+			return err
+		}
+
+		yield(row, offset)
+		offset += 4 + size
+	}
+}
+
+// migrateLegacyFile rewrites a table file in the legacy format with pages,
+// the new file replaces the old one only once all of its rows are written.
+// The file is only checked until it's known to have pages, callers hold
+// the lock of the table, so it's never migrated twice at the same time.
+func (s *fileStorage) migrateLegacyFile() error {
+	if s.migrated.Load() {
+		return nil
+	}
+
+	file, err := os.OpenFile(s.fileName(), os.O_RDONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	legacy, err := isLegacyFile(file)
+	if err != nil {
+		return err
+	}
+
+	if !legacy {
+		s.migrated.Store(true)
+		return nil
+	}
+
+	tmp, err := os.Create(s.fileName() + ".migrating")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	p := newPage()
+	pages := uint32(0)
+
+	// rows are yielded one by one, so the first error
+	// writing them is kept until they've all been read
+	var writeErr error
+	err = readLegacyRows(context.Background(), file, func(row []byte, _ int64) {
+		if writeErr != nil {
+			return
+		}
+
+		if _, ok := p.insert(row); ok {
+			return
+		}
+
+		if writeErr = writePage(tmp, pages, p); writeErr != nil {
+			return
+		}
+
+		pages++
+		p = newPage()
+		if _, ok := p.insert(row); !ok {
+			writeErr = fmt.Errorf("row of %d bytes doesn't fit in a page", len(row))
+		}
+	})
+	if err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't migrate table '%s' to pages: %w", s.table.Name, err)
+	}
+
+	if p.slots() > 0 {
+		if err := writePage(tmp, pages, p); err != nil {
+			return err
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), s.fileName()); err != nil {
+		return err
+	}
+
+	// the pool may have the legacy file open
+	if err := s.pool.invalidate(s.fileName()); err != nil {
+		return err
+	}
+
+	s.migrated.Store(true)
+
+	return nil
+}
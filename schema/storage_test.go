@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestTable(t *testing.T) *Table {
+	table := &Table{
+		ID:   1,
+		Name: "test",
+		Columns: []*Column{
+			{ID: 1, Name: "n", Type: Int64Type},
+			{ID: 2, Name: "s", Type: StringType},
+		},
+	}
+	table.storage = NewFileEngine(t.TempDir(), NewBufferPool(DefaultBufferPoolPages)).OpenTable(table)
+
+	return table
+}
+
+func fileStorageOf(table *Table) *fileStorage {
+	return table.storage.(*fileStorage)
+}
+
+func readAll(t *testing.T, table *Table) []*DeserializedRow {
+	var rows []*DeserializedRow
+	err := table.Read(context.Background(), &bytes.Buffer{}, nil, func(r *DeserializedRow) (bool, error) {
+		rows = append(rows, r)
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rows
+}
+
+// storeRow writes the row by a transaction of its own.
+func storeRow(table *Table, values []string) (RowID, error) {
+	blobs, err := table.convertValuesToBlob(values)
+	if err != nil {
+		return RowID{}, err
+	}
+
+	s := fileStorageOf(table)
+	tx := s.pool.begin()
+	id, err := s.write(tx, blobs)
+
+	return id, tx.end(err)
+}
+
+// serializeValues gives the row as it's stored in the pages of the table file.
+func serializeValues(table *Table, values []string) ([]byte, error) {
+	blobs, err := table.convertValuesToBlob(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.serializeRow(blobs)
+}
+
+func TestTablePages(t *testing.T) {
+	table := newTestTable(t)
+
+	padding := strings.Repeat("x", 500)
+	ids := make([]RowID, 100)
+	for i := range ids {
+		id, err := storeRow(table, []string{strconv.Itoa(i), padding})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ids[i] = id
+	}
+
+	info, err := os.Stat(fileStorageOf(table).fileName())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if info.Size()%PageSize != 0 || info.Size()/PageSize < 2 {
+		t.Errorf("expected the rows to take whole pages, but the file has %d bytes", info.Size())
+	}
+
+	rows := readAll(t, table)
+	if len(rows) != len(ids) {
+		t.Errorf("expected %d rows, but got %d", len(ids), len(rows))
+		return
+	}
+
+	for i, r := range rows {
+		if r.ID() != ids[i] || r.Values()[0] != int64(i) {
+			t.Errorf("expected row %d at %s, but got %v at %s", i, ids[i], r.Values()[0], r.ID())
+		}
+	}
+
+	r, err := table.Fetch(ids[42])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if r.Values()[0] != int64(42) {
+		t.Errorf("expected to fetch row 42, but got %v", r.Values()[0])
+	}
+
+	if _, err := table.Fetch(RowID{Page: ids[0].Page, Slot: 1000}); err == nil {
+		t.Error("expected fetching a missing slot to fail")
+	}
+
+	_, err = storeRow(table, []string{"1", strings.Repeat("x", PageSize)})
+	if err == nil || !strings.Contains(err.Error(), "doesn't fit in a page") {
+		t.Errorf("expected a row larger than a page to be rejected, but got %v", err)
+	}
+}
+
+func TestLegacyTableFile(t *testing.T) {
+	table := newTestTable(t)
+
+	// size prefixed rows, as tables used to be written
+	var legacy []byte
+	for i := 0; i < 3; i++ {
+		row, err := serializeValues(table, []string{strconv.Itoa(i), "legacy"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		legacy = binary.LittleEndian.AppendUint32(legacy, uint32(len(row)))
+		legacy = append(legacy, row...)
+	}
+
+	if err := os.WriteFile(fileStorageOf(table).fileName(), legacy, 0666); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if rows := readAll(t, table); len(rows) != 3 || rows[2].Values()[0] != int64(2) {
+		t.Errorf("expected to read the 3 legacy rows, but got %d", len(rows))
+		return
+	}
+
+	if fileStorageOf(table).migrated.Load() {
+		t.Error("expected reading not to migrate the file")
+	}
+
+	// writing migrates the file to pages, which is
+	// remembered so it isn't checked again
+	id, err := storeRow(table, []string{"3", "paged"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !fileStorageOf(table).migrated.Load() {
+		t.Error("expected the file to be known to have pages")
+	}
+
+	if id != (RowID{Page: 0, Slot: 3}) {
+		t.Errorf("expected the new row after the migrated ones, but got %s", id)
+	}
+
+	rows := readAll(t, table)
+	if len(rows) != 4 {
+		t.Errorf("expected 4 rows after migrating, but got %d", len(rows))
+		return
+	}
+
+	for i, r := range rows {
+		if r.Values()[0] != int64(i) || r.ID() != (RowID{Page: 0, Slot: uint16(i)}) {
+			t.Errorf("expected row %d at (0,%d), but got %v at %s", i, i, r.Values()[0], r.ID())
+		}
+	}
+}
@@ -4,13 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jvitoroc/gobase/eval"
 )
 
 type ColumnDataType string
@@ -19,31 +27,261 @@ const (
 	StringType ColumnDataType = "string"
 	BoolType   ColumnDataType = "bool"
 	Int32Type  ColumnDataType = "int"
+	Int64Type  ColumnDataType = "bigint"
+	FloatType  ColumnDataType = "float"
+
+	// strings of at most as many characters as the length of
+	// the column, chars are padded with spaces to that length
+	VarcharType ColumnDataType = "varchar"
+	CharType    ColumnDataType = "char"
+
+	DateType      ColumnDataType = "date"
+	TimeType      ColumnDataType = "time"
+	TimestampType ColumnDataType = "timestamp"
+
+	DecimalType ColumnDataType = "decimal"
+
+	// bytes are written in hexadecimal when inserted
+	// and as base64 when rows are read
+	BytesType ColumnDataType = "bytes"
+
+	UUIDType ColumnDataType = "uuid"
+
+	// json documents are validated and stored without
+	// insignificant whitespace
+	JSONType ColumnDataType = "json"
+
+	// enums store the position of their label in the
+	// enumerated type, which is given by the column
+	EnumType ColumnDataType = "enum"
 )
 
-func checkValueType(_type ColumnDataType, value string) bool {
-	switch _type {
+// ArrayOf gives the type of arrays whose elements are of the given type.
+func ArrayOf(t ColumnDataType) ColumnDataType {
+	return t + "[]"
+}
+
+func checkValueType(c *Column, value string) bool {
+	if c.IsArray() {
+		elements, err := arrayElements(value)
+		if err != nil {
+			return false
+		}
+
+		e := c.Element()
+		for _, v := range elements {
+			if !checkValueType(e, v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	switch c.Type {
 	case BoolType:
 		_, err := strconv.ParseBool(value)
 		return err == nil
 	case Int32Type:
 		_, err := strconv.ParseInt(value, 10, 32)
 		return err == nil
+	case Int64Type:
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case FloatType:
+		v, err := strconv.ParseFloat(value, 64)
+		return err == nil && !math.IsNaN(v) && !math.IsInf(v, 0)
+	case DateType:
+		_, err := parseDate(value)
+		return err == nil
+	case TimeType:
+		_, err := eval.ParseTime(value)
+		return err == nil
+	case TimestampType:
+		_, err := eval.ParseTimestamp(value)
+		return err == nil
+	case DecimalType:
+		_, err := c.decimal(value)
+		return err == nil
+	case BytesType:
+		_, err := hex.DecodeString(value)
+		return err == nil
+	case UUIDType:
+		_, err := uuid.Parse(value)
+		return err == nil
+	case JSONType:
+		_, err := eval.ParseJSON(value)
+		return err == nil
 	case StringType:
 		return true
+	case VarcharType, CharType:
+		return utf8.RuneCountInString(value) <= c.Length
+	case EnumType:
+		_, err := c.Enum.Value(value)
+		return err == nil
 	}
 
 	return false
 }
 
+// MaxLength is the greatest length of varchar and char columns.
+const MaxLength = 65535
+
 type Column struct {
 	ID   uint32
 	Name string
 	Type ColumnDataType
+
+	// total and fractional digits of decimal columns
+	Precision int `json:",omitempty"`
+	Scale     int `json:",omitempty"`
+
+	// characters of varchar and char columns
+	Length int `json:",omitempty"`
+
+	// type of enum columns
+	Enum *eval.EnumType `json:",omitempty"`
+}
+
+// IsArray tells if the column holds arrays, the rest
+// of its definition applies to their elements.
+func (c *Column) IsArray() bool {
+	return strings.HasSuffix(string(c.Type), "[]")
+}
+
+// Element returns a column like the array column c, but holding its elements.
+func (c *Column) Element() *Column {
+	e := *c
+	e.Type = ColumnDataType(strings.TrimSuffix(string(c.Type), "[]"))
+	return &e
+}
+
+// TypeName returns the type of the column along with its modifiers,
+// such as decimal(10,2).
+func (c *Column) TypeName() string {
+	if c.IsArray() {
+		return c.Element().TypeName() + "[]"
+	}
+
+	if c.Type == DecimalType {
+		return fmt.Sprintf("%s(%d,%d)", c.Type, c.Precision, c.Scale)
+	}
+
+	if c.Type == VarcharType || c.Type == CharType {
+		return fmt.Sprintf("%s(%d)", c.Type, c.Length)
+	}
+
+	if c.Type == EnumType && c.Enum != nil {
+		return c.Enum.Name
+	}
+
+	return string(c.Type)
 }
 
-func blobToGoType(_type ColumnDataType, value []byte) (any, error) {
-	switch _type {
+// CheckDefinition checks the modifiers of the column's type.
+func (c *Column) CheckDefinition() error {
+	if c.IsArray() {
+		e := c.Element()
+		if e.IsArray() {
+			return fmt.Errorf("column '%s' can't be an array of arrays", c.Name)
+		}
+
+		return e.CheckDefinition()
+	}
+
+	if c.Type == EnumType && c.Enum == nil {
+		return fmt.Errorf("type of enum column '%s' is not defined", c.Name)
+	}
+
+	if c.Type == VarcharType || c.Type == CharType {
+		if c.Length < 1 || c.Length > MaxLength {
+			return fmt.Errorf("length of column '%s' must be between 1 and %d, but got %d", c.Name, MaxLength, c.Length)
+		}
+
+		return nil
+	}
+
+	if c.Type != DecimalType {
+		return nil
+	}
+
+	if c.Precision < 1 || c.Precision > eval.MaxDecimalPrecision {
+		return fmt.Errorf("precision of column '%s' must be between 1 and %d, but got %d", c.Name, eval.MaxDecimalPrecision, c.Precision)
+	}
+
+	if c.Scale < 0 || c.Scale > c.Precision {
+		return fmt.Errorf("scale of column '%s' must be between 0 and its precision, but got %d", c.Name, c.Scale)
+	}
+
+	return nil
+}
+
+func (c *Column) CheckValue(value string) error {
+	if c.Type == DecimalType {
+		if _, err := c.decimal(value); err != nil {
+			return fmt.Errorf("column '%s' data type is %s, %w", c.Name, c.TypeName(), err)
+		}
+	}
+
+	if checkValueType(c, value) {
+		return nil
+	}
+
+	if c.Type == VarcharType || c.Type == CharType {
+		return fmt.Errorf("column '%s' data type is %s, value '%s' is too long for this column", c.Name, c.TypeName(), value)
+	}
+
+	return fmt.Errorf("column '%s' data type is %s, value '%s' is invalid for this column", c.Name, c.TypeName(), value)
+}
+
+// parseDate parses the value of a date column, timestamps such
+// as the ones NOW() gives are accepted too, keeping only their day.
+func parseDate(value string) (eval.Date, error) {
+	d, err := eval.ParseDate(value)
+	if err == nil {
+		return d, nil
+	}
+
+	t, terr := eval.ParseTimestamp(value)
+	if terr != nil {
+		return eval.Date{}, err
+	}
+
+	return eval.NewDate(t.Date()), nil
+}
+
+// decimal parses the value at the scale of the column, values are never
+// rounded, so they can't have more fractional digits than the scale holds,
+// and the digits before the decimal point must fit in its precision.
+func (c *Column) decimal(value string) (eval.Decimal, error) {
+	d, err := eval.ParseDecimal(value)
+	if err != nil {
+		return eval.Decimal{}, err
+	}
+
+	scaled, err := d.Round(c.Scale)
+	if err != nil {
+		return eval.Decimal{}, err
+	}
+
+	if !scaled.Equal(d) {
+		return eval.Decimal{}, fmt.Errorf("value '%s' has more fractional digits than %s holds", value, c.TypeName())
+	}
+	d = scaled
+
+	if d.IntegerDigits() > c.Precision-c.Scale {
+		return eval.Decimal{}, fmt.Errorf("value '%s' doesn't fit in %s", value, c.TypeName())
+	}
+
+	return d, nil
+}
+
+func blobToGoType(c *Column, value []byte) (any, error) {
+	if c.IsArray() {
+		return blobToArray(c.Element(), value)
+	}
+
+	switch c.Type {
 	case BoolType:
 		if value[0] == 01 {
 			return true, nil
@@ -51,21 +289,51 @@ func blobToGoType(_type ColumnDataType, value []byte) (any, error) {
 			return false, nil
 		}
 	case Int32Type:
-		v := binary.LittleEndian.Uint32(value)
+		// int columns keep their original sign-magnitude layout,
+		// so files written before bigint existed are still readable
+		v := int64(binary.LittleEndian.Uint32(value))
 		if value[4] == 1 {
-			return float64(int32(v)), nil
+			return v, nil
 		} else {
-			return float64(int32(v) * -1), nil
+			return v * -1, nil
 		}
-	case StringType:
+	case Int64Type:
+		return decodeInt64(value), nil
+	case FloatType:
+		return math.Float64frombits(binary.LittleEndian.Uint64(value)), nil
+	case DateType:
+		days := int32(binary.BigEndian.Uint32(value) ^ (1 << 31))
+		return eval.DateFromDays(int64(days)), nil
+	case TimeType:
+		return eval.TimeOfDay(time.Duration(decodeInt64(value)) * time.Microsecond), nil
+	case TimestampType:
+		return time.UnixMicro(decodeInt64(value)).UTC(), nil
+	case DecimalType:
+		return eval.NewDecimal(decodeInt64(value), c.Scale), nil
+	case BytesType:
+		return bytes.Clone(value), nil
+	case UUIDType:
+		return uuid.FromBytes(value)
+	case JSONType:
+		return eval.JSON(value), nil
+	case EnumType:
+		return c.Enum.ValueAt(int(binary.BigEndian.Uint16(value)))
+	case StringType, VarcharType:
 		return string(value), nil
+	case CharType:
+		// the padding isn't part of the value
+		return strings.TrimRight(string(value), " "), nil
 	}
 
 	return nil, errors.New("unsupported type")
 }
 
-func stringToBlob(_type ColumnDataType, value string) ([]byte, error) {
-	switch _type {
+func stringToBlob(c *Column, value string) ([]byte, error) {
+	if c.IsArray() {
+		return arrayToBlob(c.Element(), value)
+	}
+
+	switch c.Type {
 	case BoolType:
 		v, _ := strconv.ParseBool(value)
 		if v {
@@ -84,23 +352,210 @@ func stringToBlob(_type ColumnDataType, value string) ([]byte, error) {
 		}
 		binary.LittleEndian.PutUint32(blob, uint32(v))
 		return blob, nil
-	case StringType:
+	case Int64Type:
+		v, _ := strconv.ParseInt(value, 10, 64)
+		return encodeInt64(v), nil
+	case FloatType:
+		blob := make([]byte, 8)
+		v, _ := strconv.ParseFloat(value, 64)
+		binary.LittleEndian.PutUint64(blob, math.Float64bits(v))
+		return blob, nil
+	case DateType:
+		// days since the epoch with the sign bit flipped,
+		// just like encodeInt64 but in half the space
+		blob := make([]byte, 4)
+		v, _ := parseDate(value)
+		binary.BigEndian.PutUint32(blob, uint32(int32(v.Days()))^(1<<31))
+		return blob, nil
+	case TimeType:
+		v, _ := eval.ParseTime(value)
+		return encodeInt64(int64(time.Duration(v) / time.Microsecond)), nil
+	case TimestampType:
+		v, _ := eval.ParseTimestamp(value)
+		return encodeInt64(v.UnixMicro()), nil
+	case DecimalType:
+		// every value of the column has the same scale,
+		// so their unscaled values sort like the decimals
+		v, err := c.decimal(value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeInt64(v.Unscaled()), nil
+	case BytesType:
+		return hex.DecodeString(value)
+	case UUIDType:
+		v, err := uuid.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return v[:], nil
+	case JSONType:
+		v, err := eval.ParseJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(v), nil
+	case EnumType:
+		v, err := c.Enum.Value(value)
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.AppendUint16(nil, uint16(v.Index())), nil
+	case StringType, VarcharType:
 		return []byte(value), nil
+	case CharType:
+		return []byte(value + strings.Repeat(" ", c.Length-utf8.RuneCountInString(value))), nil
 	}
 
 	return nil, errors.New("unsupported type")
 }
 
+// FormatValue writes a value resulting from an expression the same way it
+// would be written in a query, so it can be checked and stored by a column.
+func FormatValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case []byte:
+		return hex.EncodeToString(t)
+	case eval.Array:
+		elements := make([]string, len(t))
+		for i, e := range t {
+			elements[i] = FormatValue(e)
+		}
+		blob, _ := json.Marshal(elements)
+		return string(blob)
+	case fmt.Stringer:
+		return t.String()
+	}
+
+	return fmt.Sprint(v)
+}
+
+// arrayElements reads the elements of an array written as a json array,
+// strings are unquoted and any other element is kept as it's written,
+// so ["a", "b"] and [1, 2] are both valid.
+func arrayElements(value string) ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, err
+	}
+
+	elements := make([]string, len(raw))
+	for i, r := range raw {
+		switch {
+		case r[0] == '"':
+			if err := json.Unmarshal(r, &elements[i]); err != nil {
+				return nil, err
+			}
+		case r[0] == '[' || r[0] == '{' || string(r) == "null":
+			return nil, fmt.Errorf("invalid array element '%s'", r)
+		default:
+			elements[i] = string(r)
+		}
+	}
+
+	return elements, nil
+}
+
+// arrayToBlob writes the amount of elements followed by
+// each of them prefixed by its size, like the row format.
+func arrayToBlob(e *Column, value string) ([]byte, error) {
+	elements, err := arrayElements(value)
+	if err != nil {
+		return nil, err
+	}
+
+	blob := binary.LittleEndian.AppendUint32(nil, uint32(len(elements)))
+	for _, v := range elements {
+		b, err := stringToBlob(e, v)
+		if err != nil {
+			return nil, err
+		}
+
+		blob = binary.LittleEndian.AppendUint32(blob, uint32(len(b)))
+		blob = append(blob, b...)
+	}
+
+	return blob, nil
+}
+
+func blobToArray(e *Column, blob []byte) (any, error) {
+	if len(blob) < 4 {
+		return nil, errors.New("invalid array")
+	}
+
+	n := binary.LittleEndian.Uint32(blob)
+	blob = blob[4:]
+
+	a := make(eval.Array, 0, n)
+	for i := uint32(0); i < n; i++ {
+		if len(blob) < 4 {
+			return nil, errors.New("invalid array")
+		}
+
+		size := binary.LittleEndian.Uint32(blob)
+		blob = blob[4:]
+		if uint32(len(blob)) < size {
+			return nil, errors.New("invalid array")
+		}
+
+		v, err := blobToGoType(e, blob[:size])
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, v)
+		blob = blob[size:]
+	}
+
+	return a, nil
+}
+
+// encodeInt64 writes v as big-endian two's-complement with the sign bit
+// flipped, that way comparing the encoded bytes gives the same order as
+// comparing the integers.
+func encodeInt64(v int64) []byte {
+	blob := make([]byte, 8)
+	binary.BigEndian.PutUint64(blob, uint64(v)^(1<<63))
+	return blob
+}
+
+func decodeInt64(blob []byte) int64 {
+	return int64(binary.BigEndian.Uint64(blob) ^ (1 << 63))
+}
+
 type Table struct {
 	ID      uint32
 	Name    string
 	Columns []*Column
+	Indexes []*Index `json:",omitempty"`
+
+	Compression Compression `json:",omitempty"`
+	Layout      Layout      `json:",omitempty"`
 
-	rootDir string
+	storage TableStorage
+
+	// serializes writes to the table and its indexes
+	mu sync.Mutex
 }
 
-func (t *Table) fileName() string {
-	return path.Join(t.rootDir, strconv.FormatUint(uint64(t.ID), 10))
+// ColumnNames returns the name of each column in the order they are stored.
+func (t *Table) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+
+	return names
 }
 
 func (t *Table) Insert(values []string) error {
@@ -109,16 +564,52 @@ func (t *Table) Insert(values []string) error {
 	}
 
 	for i, c := range t.Columns {
-		if ok := checkValueType(c.Type, values[i]); !ok {
-			return fmt.Errorf("column '%s' data type is %s, value '%s' is invalid for this column", c.Name, c.Type, values[i])
+		if err := c.CheckValue(values[i]); err != nil {
+			return err
 		}
 	}
 
-	return t.write(values)
+	blobs, err := t.convertValuesToBlob(values)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// the keys are checked before the row is stored, so
+	// it isn't stored when it can't be added to an index
+	keys, err := t.indexKeys(blobs)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.storage.Insert(blobs, keys)
+	return err
+}
+
+// Fetch reads the row with the given identifier.
+func (t *Table) Fetch(id RowID) (*DeserializedRow, error) {
+	blobs, err := t.storage.Fetch(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.deserializeRow(id, blobs)
 }
 
 type DeserializedRow struct {
 	Columns []*DeserializedColumn
+
+	// values of the columns in the same order as the table's columns
+	values []any
+
+	id RowID
+}
+
+// ID returns the page and slot the row is stored in.
+func (d *DeserializedRow) ID() RowID {
+	return d.id
 }
 
 func (d *DeserializedRow) GetColumn(name string) *DeserializedColumn {
@@ -131,6 +622,11 @@ func (d *DeserializedRow) GetColumn(name string) *DeserializedColumn {
 	return nil
 }
 
+// Values returns the values of the row ordered as the table's columns.
+func (d *DeserializedRow) Values() []any {
+	return d.values
+}
+
 func (d *DeserializedRow) Map() map[string]any {
 	m := make(map[string]any, len(d.Columns))
 	for _, c := range d.Columns {
@@ -140,37 +636,60 @@ func (d *DeserializedRow) Map() map[string]any {
 	return m
 }
 
+// Read writes every row shouldInclude includes. Columns are the ones
+// shouldInclude reads, nil standing for all of them, storages can give
+// it rows holding only those columns.
 func (t *Table) Read(ctx context.Context, wr io.Writer, columns []string, shouldInclude func(*DeserializedRow) (bool, error)) error {
-	ch := t.createReader(ctx)
+	filtered := t.Columns
+	if columns != nil {
+		filtered = slices.DeleteFunc(slices.Clone(t.Columns), func(c *Column) bool {
+			return !slices.Contains(columns, c.Name)
+		})
+	}
 
-	for row := range ch {
-		switch r := row.(type) {
-		case []byte:
-			dr, err := t.deserializeRow(r)
-			if err != nil {
-				return err
-			}
+	// the last row shouldInclude was given is written as it
+	// is when it's the one yielded and holds every column
+	var included *DeserializedRow
+	include := func(id RowID, blobs map[uint32][]byte) (bool, error) {
+		r, err := t.deserializeRow(id, blobs)
+		if err != nil {
+			return false, err
+		}
+		included = r
 
-			include, err := shouldInclude(dr)
-			if err != nil {
+		return shouldInclude(r)
+	}
+
+	return t.storage.Scan(ctx, filtered, include, func(id RowID, blobs map[uint32][]byte) error {
+		r := included
+		if r == nil || r.id != id || len(r.Columns) != len(t.Columns) {
+			var err error
+			if r, err = t.deserializeRow(id, blobs); err != nil {
 				return err
 			}
+		}
 
-			if !include {
-				continue
-			}
+		return marshalRow(wr, r)
+	})
+}
 
-			drJson, err := json.Marshal(dr)
-			if err != nil {
-				return err
-			}
+func writeRow(wr io.Writer, dr *DeserializedRow, shouldInclude func(*DeserializedRow) (bool, error)) error {
+	include, err := shouldInclude(dr)
+	if err != nil || !include {
+		return err
+	}
 
-			wr.Write(drJson)
-		case error:
-			return r
-		}
+	return marshalRow(wr, dr)
+}
+
+func marshalRow(wr io.Writer, dr *DeserializedRow) error {
+	drJson, err := json.Marshal(dr)
+	if err != nil {
+		return err
 	}
 
+	wr.Write(drJson)
+
 	return nil
 }
 
@@ -179,29 +698,38 @@ type DeserializedColumn struct {
 	Value any
 }
 
-func (t *Table) deserializeRow(row []byte) (*DeserializedRow, error) {
-	m, err := deserializeColumns(row)
-	if err != nil {
-		return nil, err
-	}
-
+// deserializeRow decodes the stored values of the row,
+// the columns it has no value for are left out of it.
+func (t *Table) deserializeRow(id RowID, blobs map[uint32][]byte) (*DeserializedRow, error) {
 	r := &DeserializedRow{
-		Columns: make([]*DeserializedColumn, 0, len(t.Columns)),
+		Columns: make([]*DeserializedColumn, 0, len(blobs)),
+		values:  make([]any, len(t.Columns)),
+		id:      id,
 	}
-	for _, c := range t.Columns {
-		v, err := blobToGoType(c.Type, m[c.ID])
+
+	for i, c := range t.Columns {
+		blob, ok := blobs[c.ID]
+		if !ok {
+			continue
+		}
+
+		v, err := blobToGoType(c, blob)
 		if err != nil {
 			return nil, err
 		}
-		r.Columns = append(r.Columns, &DeserializedColumn{
-			Column: c,
-			Value:  v,
-		})
+
+		r.Columns = append(r.Columns, &DeserializedColumn{Column: c, Value: v})
+		r.values[i] = v
 	}
 
 	return r, nil
 }
 
+// storedColumns gives the value of each column of a row as it's stored.
+func (t *Table) storedColumns(data []byte) (map[uint32][]byte, error) {
+	return deserializeColumns(data)
+}
+
 func deserializeColumns(row []byte) (map[uint32][]byte, error) {
 	r := bytes.NewReader(row)
 	mappedRow := make(map[uint32][]byte)
@@ -226,8 +754,10 @@ func deserializeColumns(row []byte) (map[uint32][]byte, error) {
 
 		valueSize := binary.LittleEndian.Uint32(int32Bytes)
 
+		// values can be empty, in which case ReadFull reads nothing
+		// instead of failing when they're at the end of the row
 		value := make([]byte, valueSize)
-		_, err = r.Read(value)
+		_, err = io.ReadFull(r, value)
 		if err != nil {
 			return nil, err
 		}
@@ -238,109 +768,18 @@ func deserializeColumns(row []byte) (map[uint32][]byte, error) {
 	return mappedRow, nil
 }
 
-func (t *Table) createReader(ctx context.Context) chan any {
-	ch := make(chan any, 1)
-
-	go func() {
-		file, err := os.OpenFile(t.fileName(), os.O_RDONLY|os.O_CREATE, 0666)
-		if err != nil {
-			panic(err)
-		}
-		defer file.Close()
-		defer close(ch)
-
-		for {
-			select {
-			case <-ctx.Done():
-				ch <- ctx.Err()
-				return
-			default:
-				rowSizeBytes := make([]byte, 4)
-				n, err := file.Read(rowSizeBytes)
-				if errors.Is(err, io.EOF) {
-					return
-				}
-
-				if err != nil {
-					ch <- err
-					return
-				}
-
-				if n != 4 {
-					ch <- errors.New("read invalid amount of bytes")
-					return
-				}
-
-				rowSize := binary.LittleEndian.Uint32(rowSizeBytes)
-				rowBytes := make([]byte, rowSize)
-				n, err = file.Read(rowBytes)
-				if err != nil {
-					ch <- err
-					return
-				}
-
-				if n != int(rowSize) {
-					ch <- errors.New("read invalid amount of bytes")
-					return
-				}
-
-				ch <- rowBytes
-			}
-		}
-	}()
-
-	return ch
-}
-
-func (t *Table) convertValuesToBlob(values []string) ([][]byte, error) {
-	valuesBlob := make([][]byte, len(values))
+// convertValuesToBlob gives the values of the row as their columns store them.
+func (t *Table) convertValuesToBlob(values []string) (map[uint32][]byte, error) {
+	blobs := make(map[uint32][]byte, len(t.Columns))
 
 	for i, c := range t.Columns {
-		blob, err := stringToBlob(c.Type, values[i])
+		blob, err := stringToBlob(c, values[i])
 		if err != nil {
 			return nil, err
 		}
 
-		valuesBlob[i] = blob
-	}
-
-	return valuesBlob, nil
-}
-
-func (t *Table) write(values []string) error {
-	file, err := os.OpenFile(t.fileName(), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0666)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	var row []byte
-	valuesBlob, err := t.convertValuesToBlob(values)
-	if err != nil {
-		return err
+		blobs[c.ID] = blob
 	}
 
-	for i, c := range t.Columns {
-		int32Bytes := make([]byte, 4)
-
-		binary.LittleEndian.PutUint32(int32Bytes, c.ID)
-		row = append(row, int32Bytes...) // write column id
-
-		valueSize := uint32(len(valuesBlob[i]))
-		binary.LittleEndian.PutUint32(int32Bytes, valueSize)
-		row = append(row, int32Bytes...) // write column size
-
-		row = append(row, valuesBlob[i]...)
-	}
-
-	rowSizeBytes := make([]byte, 4)
-	binary.LittleEndian.PutUint32(rowSizeBytes, uint32(len(row)))
-	blob := append(rowSizeBytes, row...)
-
-	_, err = file.Write(blob)
-	if err != nil {
-		return fmt.Errorf("an error occurred writing row to disk: %w", err)
-	}
-
-	return nil
+	return blobs, nil
 }
@@ -0,0 +1,546 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/jvitoroc/gobase/eval"
+)
+
+func TestInt64Encoding(t *testing.T) {
+	values := []int64{math.MinInt64, -1700000000000, -256, -1, 0, 1, 255, 1700000000000, math.MaxInt64}
+
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		blob, err := stringToBlob(&Column{Type: Int64Type}, strconv.FormatInt(v, 10))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(blob) != 8 {
+			t.Errorf("expected 8 bytes for %d, but got %d", v, len(blob))
+			return
+		}
+
+		got, err := blobToGoType(&Column{Type: Int64Type}, blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if got != v {
+			t.Errorf("expected %d after decoding, but got %v", v, got)
+			return
+		}
+
+		encoded[i] = blob
+	}
+
+	if !slices.IsSortedFunc(encoded, bytes.Compare) {
+		t.Error("encoding doesn't preserve the order of the integers")
+	}
+}
+
+func TestInt32LegacyEncoding(t *testing.T) {
+	// blobs written before integers were decoded as int64
+	tests := []struct {
+		blob []byte
+		want int64
+	}{
+		{blob: []byte{123, 0, 0, 0, 1}, want: 123},
+		{blob: []byte{123, 0, 0, 0, 0}, want: -123},
+		{blob: []byte{0, 0, 0, 128, 0}, want: math.MinInt32},
+		{blob: []byte{255, 255, 255, 127, 1}, want: math.MaxInt32},
+	}
+
+	for i, tt := range tests {
+		got, err := blobToGoType(&Column{Type: Int32Type}, tt.blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if got != tt.want {
+			t.Errorf("test %d failed: expected %d, but got %v", i+1, tt.want, got)
+		}
+
+		blob, err := stringToBlob(&Column{Type: Int32Type}, strconv.FormatInt(tt.want, 10))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !bytes.Equal(blob, tt.blob) {
+			t.Errorf("test %d failed: expected blob %v, but got %v", i+1, tt.blob, blob)
+		}
+	}
+}
+
+func TestCheckValueTypeBigint(t *testing.T) {
+	c := &Column{Name: "id", Type: Int64Type}
+
+	if err := c.CheckValue("1700000000000"); err != nil {
+		t.Error(err)
+	}
+
+	if err := c.CheckValue("9223372036854775808"); err == nil {
+		t.Error("expected out of range value to be rejected")
+	}
+
+	if err := c.CheckValue("1.5"); err == nil {
+		t.Error("expected decimal value to be rejected")
+	}
+}
+
+func TestFloatEncoding(t *testing.T) {
+	values := []string{"0", "1.5", "-2.25", "123.321", "1e300", "5e-324"}
+
+	for _, v := range values {
+		blob, err := stringToBlob(&Column{Type: FloatType}, v)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		got, err := blobToGoType(&Column{Type: FloatType}, blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		want, _ := strconv.ParseFloat(v, 64)
+		if got != want {
+			t.Errorf("expected %v after decoding, but got %v", want, got)
+		}
+	}
+
+	c := &Column{Name: "f", Type: FloatType}
+	for _, v := range []string{"NaN", "Inf", "-Inf", "abc"} {
+		if err := c.CheckValue(v); err == nil {
+			t.Errorf("expected '%s' to be rejected", v)
+		}
+	}
+}
+
+func TestTemporalEncoding(t *testing.T) {
+	tests := []struct {
+		_type  ColumnDataType
+		values []string
+		size   int
+	}{
+		{
+			_type:  DateType,
+			values: []string{"0001-01-01", "1969-12-31", "1970-01-01", "2024-02-29", "9999-12-31"},
+			size:   4,
+		},
+		{
+			_type:  TimeType,
+			values: []string{"00:00", "00:00:00.000001", "12:30:15.5", "23:59:59.999999"},
+			size:   8,
+		},
+		{
+			_type:  TimestampType,
+			values: []string{"1900-01-01T00:00:00Z", "1969-12-31T23:59:59.999999Z", "2024-01-31T10:00:00+01:00", "2024-01-31T10:00:00Z"},
+			size:   8,
+		},
+	}
+
+	for _, tt := range tests {
+		encoded := make([][]byte, len(tt.values))
+		for i, v := range tt.values {
+			if !checkValueType(&Column{Type: tt._type}, v) {
+				t.Errorf("expected '%s' to be a valid %s", v, tt._type)
+				return
+			}
+
+			blob, err := stringToBlob(&Column{Type: tt._type}, v)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(blob) != tt.size {
+				t.Errorf("expected %d bytes for %s '%s', but got %d", tt.size, tt._type, v, len(blob))
+				return
+			}
+
+			got, err := blobToGoType(&Column{Type: tt._type}, blob)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			s := fmt.Sprint(got)
+			if ts, ok := got.(time.Time); ok {
+				s = ts.Format(time.RFC3339Nano)
+			}
+
+			again, _ := stringToBlob(&Column{Type: tt._type}, s)
+			if !bytes.Equal(blob, again) {
+				t.Errorf("%s '%s' changed to '%v' after decoding", tt._type, v, got)
+				return
+			}
+
+			encoded[i] = blob
+		}
+
+		if !slices.IsSortedFunc(encoded, bytes.Compare) {
+			t.Errorf("%s encoding doesn't preserve the order of the values", tt._type)
+		}
+	}
+}
+
+func TestCheckValueTypeTemporal(t *testing.T) {
+	invalid := map[ColumnDataType]string{
+		DateType:      "2024-02-30",
+		TimeType:      "24:00",
+		TimestampType: "2024-01-31T25:00:00Z",
+	}
+
+	for _type, v := range invalid {
+		if checkValueType(&Column{Type: _type}, v) {
+			t.Errorf("expected '%s' to be an invalid %s", v, _type)
+		}
+	}
+}
+
+func TestDecimalEncoding(t *testing.T) {
+	c := &Column{Name: "price", Type: DecimalType, Precision: 6, Scale: 2}
+
+	values := []string{"-9999.99", "-0.5", "0", "0.01", "12.340", "9999.99"}
+	want := []string{"-9999.99", "-0.50", "0.00", "0.01", "12.34", "9999.99"}
+
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		if err := c.CheckValue(v); err != nil {
+			t.Error(err)
+			return
+		}
+
+		blob, err := stringToBlob(c, v)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		got, err := blobToGoType(c, blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if fmt.Sprint(got) != want[i] {
+			t.Errorf("expected '%s' to be stored as %s, but got %v", v, want[i], got)
+			return
+		}
+
+		encoded[i] = blob
+	}
+
+	if !slices.IsSortedFunc(encoded, bytes.Compare) {
+		t.Error("encoding doesn't preserve the order of the decimals")
+	}
+
+	for _, v := range []string{"10000", "9999.995", "12.345", "-0.005", "1.5e3", "abc"} {
+		if err := c.CheckValue(v); err == nil {
+			t.Errorf("expected '%s' to be invalid for %s", v, c.TypeName())
+		}
+	}
+}
+
+func TestCheckDefinition(t *testing.T) {
+	tests := []struct {
+		column  Column
+		wantErr string
+	}{
+		{column: Column{Name: "a", Type: DecimalType, Precision: 18, Scale: 18}},
+		{column: Column{Name: "a", Type: DecimalType, Precision: 19}, wantErr: "precision of column 'a' must be between 1 and 18, but got 19"},
+		{column: Column{Name: "a", Type: DecimalType, Precision: 0}, wantErr: "precision of column 'a' must be between 1 and 18, but got 0"},
+		{column: Column{Name: "a", Type: DecimalType, Precision: 4, Scale: 5}, wantErr: "scale of column 'a' must be between 0 and its precision, but got 5"},
+		{column: Column{Name: "a", Type: Int32Type}},
+		{column: Column{Name: "a", Type: VarcharType, Length: MaxLength}},
+		{column: Column{Name: "a", Type: VarcharType}, wantErr: "length of column 'a' must be between 1 and 65535, but got 0"},
+		{column: Column{Name: "a", Type: CharType, Length: 65536}, wantErr: "length of column 'a' must be between 1 and 65535, but got 65536"},
+	}
+
+	for i, tt := range tests {
+		err := tt.column.CheckDefinition()
+		gotErr := ""
+		if err != nil {
+			gotErr = err.Error()
+		}
+
+		if gotErr != tt.wantErr {
+			t.Errorf("test %d failed: CheckDefinition() error = '%s', wantErr '%s'", i+1, gotErr, tt.wantErr)
+		}
+	}
+}
+
+func TestBytesEncoding(t *testing.T) {
+	c := &Column{Name: "hash", Type: BytesType}
+
+	tests := []struct {
+		value string
+		want  []byte
+	}{
+		{value: "", want: []byte{}},
+		{value: "00ff", want: []byte{0x00, 0xff}},
+		{value: "DEADbeef", want: []byte{0xde, 0xad, 0xbe, 0xef}},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckValue(tt.value); err != nil {
+			t.Error(err)
+			return
+		}
+
+		blob, err := stringToBlob(c, tt.value)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		got, err := blobToGoType(c, blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if !bytes.Equal(got.([]byte), tt.want) {
+			t.Errorf("expected %x after decoding '%s', but got %x", tt.want, tt.value, got)
+		}
+	}
+
+	for _, v := range []string{"abc", "zz", "0x00"} {
+		if err := c.CheckValue(v); err == nil {
+			t.Errorf("expected '%s' to be invalid for %s", v, c.TypeName())
+		}
+	}
+}
+
+func TestUUIDEncoding(t *testing.T) {
+	c := &Column{Name: "id", Type: UUIDType}
+
+	for _, v := range []string{"0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10", "{0190A5E0-7C4B-7CC2-9A35-4C2F7A0B8F10}", "urn:uuid:0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10"} {
+		if err := c.CheckValue(v); err != nil {
+			t.Error(err)
+			return
+		}
+
+		blob, err := stringToBlob(c, v)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(blob) != 16 {
+			t.Errorf("expected 16 bytes for '%s', but got %d", v, len(blob))
+			return
+		}
+
+		got, err := blobToGoType(c, blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if fmt.Sprint(got) != "0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10" {
+			t.Errorf("expected '%s' to be decoded as the same uuid, but got %v", v, got)
+		}
+	}
+
+	for _, v := range []string{"", "0190a5e0", "0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f1z"} {
+		if err := c.CheckValue(v); err == nil {
+			t.Errorf("expected '%s' to be invalid for %s", v, c.TypeName())
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{value: "foo", want: "foo"},
+		{value: int64(-12), want: "-12"},
+		{value: 0.1, want: "0.1"},
+		{value: true, want: "true"},
+		{value: eval.NewDecimal(1050, 2), want: "10.50"},
+		{value: eval.NewDate(2024, time.January, 31), want: "2024-01-31"},
+		{value: time.Date(2024, time.January, 31, 10, 0, 0, 500, time.UTC), want: "2024-01-31T10:00:00.0000005Z"},
+		{value: []byte{0xde, 0xad}, want: "dead"},
+		{value: uuid.MustParse("0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10"), want: "0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatValue(tt.value); got != tt.want {
+			t.Errorf("FormatValue(%#v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	c := &Column{Name: "doc", Type: JSONType}
+
+	tests := []struct {
+		value string
+		want  eval.JSON
+	}{
+		{value: `{ "a": [1, 2 ,3], "b" : null }`, want: `{"a":[1,2,3],"b":null}`},
+		{value: ` "text" `, want: `"text"`},
+		{value: `12.50`, want: `12.50`},
+	}
+
+	for _, tt := range tests {
+		if err := c.CheckValue(tt.value); err != nil {
+			t.Error(err)
+			return
+		}
+
+		blob, err := stringToBlob(c, tt.value)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		got, err := blobToGoType(c, blob)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if got != tt.want {
+			t.Errorf("expected %s after decoding '%s', but got %s", tt.want, tt.value, got)
+		}
+	}
+
+	for _, v := range []string{"", "{", "{'a': 1}", "[1,]"} {
+		if err := c.CheckValue(v); err == nil {
+			t.Errorf("expected '%s' to be invalid for %s", v, c.TypeName())
+		}
+	}
+}
+
+func TestLengthBoundedStrings(t *testing.T) {
+	tests := []struct {
+		column  *Column
+		value   string
+		want    string
+		wantErr string
+	}{
+		{column: &Column{Name: "name", Type: VarcharType, Length: 3}, value: "abc", want: "abc"},
+		{column: &Column{Name: "name", Type: VarcharType, Length: 3}, value: "", want: ""},
+		{column: &Column{Name: "name", Type: VarcharType, Length: 3}, value: "ção", want: "ção"},
+		{
+			column:  &Column{Name: "name", Type: VarcharType, Length: 3},
+			value:   "abcd",
+			wantErr: "column 'name' data type is varchar(3), value 'abcd' is too long for this column",
+		},
+		{column: &Column{Name: "code", Type: CharType, Length: 4}, value: "ab", want: "ab"},
+		{column: &Column{Name: "code", Type: CharType, Length: 4}, value: "ab  ", want: "ab"},
+		{
+			column:  &Column{Name: "code", Type: CharType, Length: 1},
+			value:   "ab",
+			wantErr: "column 'code' data type is char(1), value 'ab' is too long for this column",
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.column.CheckValue(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected error '%s', but got %v", tt.wantErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		blob, err := stringToBlob(tt.column, tt.value)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if tt.column.Type == CharType && len([]rune(string(blob))) != tt.column.Length {
+			t.Errorf("expected %s to be stored padded, but got '%s'", tt.column.TypeName(), blob)
+		}
+
+		got, err := blobToGoType(tt.column, blob)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("expected '%s' after decoding '%s', but got '%s'", tt.want, tt.value, got)
+		}
+	}
+}
+
+func TestArrayEncoding(t *testing.T) {
+	tests := []struct {
+		column *Column
+		value  string
+		want   eval.Array
+	}{
+		{column: &Column{Name: "tags", Type: ArrayOf(StringType)}, value: `["go", "", "db"]`, want: eval.Array{"go", "", "db"}},
+		{column: &Column{Name: "tags", Type: ArrayOf(StringType)}, value: `[]`, want: eval.Array{}},
+		{column: &Column{Name: "scores", Type: ArrayOf(Int32Type)}, value: `[1, "-2"]`, want: eval.Array{int64(1), int64(-2)}},
+		{column: &Column{Name: "prices", Type: ArrayOf(DecimalType), Precision: 5, Scale: 2}, value: `[1.5]`, want: eval.Array{eval.NewDecimal(150, 2)}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.column.CheckValue(tt.value); err != nil {
+			t.Error(err)
+			continue
+		}
+
+		blob, err := stringToBlob(tt.column, tt.value)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		got, err := blobToGoType(tt.column, blob)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if diff := cmp.Diff(got, tt.want); diff != "" {
+			t.Errorf("%s: %s", tt.value, diff)
+		}
+
+		// values read from the column can be inserted back
+		if err := tt.column.CheckValue(FormatValue(got)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	c := &Column{Name: "codes", Type: ArrayOf(VarcharType), Length: 2}
+	if c.TypeName() != "varchar(2)[]" {
+		t.Errorf("expected varchar(2)[], but got %s", c.TypeName())
+	}
+
+	for _, v := range []string{`["abc"]`, `"ab"`, `[["ab"]]`, `[null]`} {
+		if err := c.CheckValue(v); err == nil {
+			t.Errorf("expected '%s' to be invalid for %s", v, c.TypeName())
+		}
+	}
+
+	if err := (&Column{Name: "m", Type: "int[][]"}).CheckDefinition(); err == nil {
+		t.Error("expected arrays of arrays to be rejected")
+	}
+}
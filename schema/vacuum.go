@@ -0,0 +1,355 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// vacuumSuffix is added to the name of the files a vacuum writes,
+// until they replace the ones of the table.
+const vacuumSuffix = ".vacuum"
+
+// VacuumStats tells how many pages the storage of a table had before and after a vacuum.
+type VacuumStats struct {
+	Table       string
+	PagesBefore uint32
+	PagesAfter  uint32
+}
+
+// Vacuum gives back the space left in the storage of the table by the rows
+// that are gone. Writes to the table wait until it's done.
+func (t *Table) Vacuum(ctx context.Context) (*VacuumStats, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.storage.Vacuum(ctx)
+}
+
+// Vacuum rewrites the table file into a new one holding only its live rows,
+// packed in as few pages as possible, along with new index files pointing to
+// them, then swaps the new files in. Readers only wait while the files are
+// swapped.
+//
+// Every rename is atomic, but they aren't as a whole, a crash between
+// them leaves the indexes that weren't renamed pointing to old rows.
+func (s *fileStorage) Vacuum(ctx context.Context) (*VacuumStats, error) {
+	if err := s.migrateLegacyFile(); err != nil {
+		return nil, err
+	}
+
+	t := s.table
+	before, err := s.pool.pageCount(s.fileName())
+	if err != nil {
+		return nil, s.corrupted(err)
+	}
+
+	// the group of a page of a columnar table is encoded again
+	// on every insert, so its pages are always as full as they get
+	if t.Layout == ColumnarLayout {
+		return &VacuumStats{Table: t.Name, PagesBefore: before, PagesAfter: before}, nil
+	}
+
+	shadows := make([]*Index, len(t.Indexes))
+	for i, x := range t.Indexes {
+		shadows[i] = &Index{Name: x.Name, Columns: x.Columns, Method: x.Method, Unique: x.Unique, table: t}
+		s.openIndex(shadows[i], s.indexFileName(x)+vacuumSuffix)
+	}
+
+	after, err := s.compact(ctx, shadows)
+	if err == nil {
+		// the log may hold pages of the files being replaced, which
+		// mustn't be written over the new ones when it's replayed
+		err = s.pool.Checkpoint()
+	}
+	if err != nil {
+		s.removeVacuumFiles()
+		return nil, err
+	}
+
+	if err := s.swapVacuumFiles(); err != nil {
+		return nil, err
+	}
+
+	return &VacuumStats{Table: t.Name, PagesBefore: before, PagesAfter: after}, nil
+}
+
+// compact writes the rows of the table to a new file, and their keys
+// to the shadow indexes, giving how many pages the new file has.
+func (s *fileStorage) compact(ctx context.Context, shadows []*Index) (uint32, error) {
+	// files left by a vacuum that didn't finish are replaced
+	if err := s.removeVacuumFiles(); err != nil {
+		return 0, err
+	}
+
+	for _, x := range shadows {
+		tx := s.pool.begin()
+		if err := tx.end(x.structure().create(tx)); err != nil {
+			return 0, err
+		}
+	}
+
+	file, err := os.Create(s.fileName() + vacuumSuffix)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	p := newPage()
+	pages := uint32(0)
+
+	err = s.readPages(ctx, func(r storedRow) error {
+		slot, ok, err := s.table.appendRow(p, r.data)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			if err := writePage(file, pages, p); err != nil {
+				return err
+			}
+
+			pages++
+			p = newPage()
+			if slot, _, err = s.table.appendRow(p, r.data); err != nil {
+				return err
+			}
+		}
+
+		return s.indexRow(shadows, r.data, RowID{Page: pages, Slot: slot})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if p.slots() > 0 {
+		if err := writePage(file, pages, p); err != nil {
+			return 0, err
+		}
+		pages++
+	}
+
+	return pages, file.Sync()
+}
+
+// indexRow adds the row to the indexes, which must have no duplicates,
+// since the row was already in the indexes the shadows are built for.
+func (s *fileStorage) indexRow(indexes []*Index, row []byte, id RowID) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	blobs, err := s.table.storedColumns(row)
+	if err != nil {
+		return err
+	}
+
+	for _, x := range indexes {
+		key, err := x.key(blobs)
+		if err != nil {
+			return err
+		}
+
+		tx := s.pool.begin()
+		if err := tx.end(x.insert(tx, key, id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fileNames gives the files of the table and its indexes.
+func (s *fileStorage) fileNames() []string {
+	files := []string{s.fileName()}
+	for _, x := range s.table.Indexes {
+		files = append(files, s.indexFileName(x))
+	}
+
+	return files
+}
+
+func (s *fileStorage) removeVacuumFiles() error {
+	for _, name := range s.fileNames() {
+		if err := s.pool.invalidate(name + vacuumSuffix); err != nil {
+			return err
+		}
+
+		if err := os.Remove(name + vacuumSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// swapVacuumFiles replaces the files of the table with the ones written
+// by the vacuum, once the readers using them are done.
+func (s *fileStorage) swapVacuumFiles() error {
+	s.files.Lock()
+	defer s.files.Unlock()
+
+	for _, name := range s.fileNames() {
+		if err := s.pool.invalidate(name + vacuumSuffix); err != nil {
+			return err
+		}
+
+		if err := s.pool.invalidate(name); err != nil {
+			return err
+		}
+
+		if err := os.Rename(name+vacuumSuffix, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reclaimable estimates how many pages a vacuum would take out of the
+// table file, from how much of its pages is taken by rows and their slots.
+func (s *fileStorage) Reclaimable() (uint32, uint32, error) {
+	s.files.RLock()
+	defer s.files.RUnlock()
+
+	legacy, err := s.isLegacy()
+	if err != nil || legacy {
+		return 0, 0, err
+	}
+
+	pages, err := s.pool.pageCount(s.fileName())
+	if err != nil {
+		return 0, 0, s.corrupted(err)
+	}
+
+	if s.table.Layout == ColumnarLayout {
+		return 0, pages, nil
+	}
+
+	used := 0
+	for n := uint32(0); n < pages; n++ {
+		fr, err := s.pool.pin(s.fileName(), n)
+		if err != nil {
+			return 0, 0, s.corrupted(err)
+		}
+
+		fr.latch.RLock()
+		used += PageSize - pageHeaderSize - fr.data.freeSpace()
+		fr.latch.RUnlock()
+		s.pool.unpin(fr)
+	}
+
+	needed := uint32((used + PageSize - pageHeaderSize - 1) / (PageSize - pageHeaderSize))
+
+	return pages - min(needed, pages), pages, nil
+}
+
+// Vacuum vacuums every table of the schema.
+func (s *Schema) Vacuum(ctx context.Context) ([]*VacuumStats, error) {
+	s.mu.Lock()
+	tables := slices.Clone(s.tables)
+	s.mu.Unlock()
+
+	var stats []*VacuumStats
+	for _, t := range tables {
+		st, err := t.Vacuum(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, nil
+}
+
+// Compactor vacuums the tables of a schema in the background.
+type Compactor struct {
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// StartCompactor checks the tables of the schema every interval, vacuuming
+// the ones in which a vacuum would reclaim at least the given fraction of
+// their pages. A vacuum that fails leaves its table as it was, so it's
+// just tried again later, Err tells why it failed in the meantime.
+func (s *Schema) StartCompactor(interval time.Duration, fraction float64) *Compactor {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Compactor{cancel: cancel}
+
+	c.done.Add(1)
+	go func() {
+		defer c.done.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.compact(ctx, fraction)
+
+				c.mu.Lock()
+				c.err = err
+				c.mu.Unlock()
+			}
+		}
+	}()
+
+	return c
+}
+
+// Stop stops the compactor, waiting for the vacuum it's running.
+func (c *Compactor) Stop() {
+	c.cancel()
+	c.done.Wait()
+}
+
+// Err gives why the tables that failed to be vacuumed the last
+// time the compactor checked them couldn't be, nil when none did.
+func (c *Compactor) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// compact vacuums the tables worth it, the ones that fail don't
+// stop the others, their errors are given together at the end.
+func (s *Schema) compact(ctx context.Context, fraction float64) error {
+	s.mu.Lock()
+	tables := slices.Clone(s.tables)
+	s.mu.Unlock()
+
+	var errs []error
+	for _, t := range tables {
+		if ctx.Err() != nil {
+			break
+		}
+
+		reclaimable, pages, err := t.storage.Reclaimable()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("couldn't vacuum table '%s': %w", t.Name, err))
+			continue
+		}
+
+		if reclaimable == 0 || float64(reclaimable) < fraction*float64(pages) {
+			continue
+		}
+
+		// a vacuum interrupted by Stop isn't a failure
+		if _, err := t.Vacuum(ctx); err != nil && ctx.Err() == nil {
+			errs = append(errs, fmt.Errorf("couldn't vacuum table '%s': %w", t.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
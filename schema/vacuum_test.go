@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newSparseTable gives a table whose rows are each in a page of their own,
+// the way pages are left once most of their rows are deleted.
+func newSparseTable(t *testing.T, rows int) *Table {
+	table := newTestTable(t)
+	pool := fileStorageOf(table).pool
+
+	for i := 0; i < rows; i++ {
+		row, err := serializeValues(table, []string{strconv.Itoa(i), "row"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tx := pool.begin()
+		fr, _, err := pool.allocate(fileStorageOf(table).fileName())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fr.data.insert(row)
+		tx.dirty(fr)
+		if err := tx.commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return table
+}
+
+func TestVacuum(t *testing.T) {
+	const rows = 100
+	table := newSparseTable(t, rows)
+	defer fileStorageOf(table).pool.Close()
+
+	x, err := table.createIndex("test_n", []string{"n"}, BTreeIndex, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reclaimable, _, err := table.storage.Reclaimable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reclaimable != rows-1 {
+		t.Errorf("expected %d pages to be reclaimable, but got %d", rows-1, reclaimable)
+	}
+
+	// readers keep reading every row while the files are swapped
+	done := make(chan struct{})
+	readErrs := make(chan error, 1)
+	go func() {
+		defer close(readErrs)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			n := 0
+			err := table.Read(context.Background(), io.Discard, nil, func(*DeserializedRow) (bool, error) {
+				n++
+				return false, nil
+			})
+			if err == nil && n != rows {
+				err = fmt.Errorf("expected %d rows while vacuuming, but got %d", rows, n)
+			}
+
+			if err != nil {
+				readErrs <- err
+				return
+			}
+		}
+	}()
+
+	stats, err := table.Vacuum(context.Background())
+	close(done)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-readErrs; err != nil {
+		t.Error(err)
+	}
+
+	if diff := cmp.Diff(&VacuumStats{Table: "test", PagesBefore: rows, PagesAfter: 1}, stats); diff != "" {
+		t.Errorf("vacuum stats (-want +got):\n%s", diff)
+	}
+
+	got := readAll(t, table)
+	for i, r := range got {
+		if v := r.Values()[0]; v != int64(i) {
+			t.Errorf("expected row %d to hold %d, but got %v", i, i, v)
+		}
+	}
+
+	if err := table.Insert([]string{"42", "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bound := &IndexBound{Values: []any{int64(42)}, Inclusive: true}
+	if diff := cmp.Diff([]int64{42, 42}, scanIndex(t, table, x, bound, bound)); diff != "" {
+		t.Errorf("scanning the vacuumed index (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompactor(t *testing.T) {
+	table := newSparseTable(t, 20)
+	defer fileStorageOf(table).pool.Close()
+
+	sch := NewSchema(fileStorageOf(table).rootDir)
+	sch.tables = append(sch.tables, table)
+
+	c := sch.StartCompactor(time.Millisecond, 0.5)
+	defer c.Stop()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, pages, err := table.storage.Reclaimable(); err == nil && pages == 1 {
+			return
+		}
+	}
+
+	t.Error("expected the compactor to vacuum the table")
+}
+
+// failingStorage always has pages to reclaim, but fails to vacuum them.
+type failingStorage struct {
+	TableStorage
+}
+
+func (failingStorage) Reclaimable() (uint32, uint32, error) {
+	return 1, 1, nil
+}
+
+func (failingStorage) Vacuum(context.Context) (*VacuumStats, error) {
+	return nil, errors.New("disk full")
+}
+
+func TestCompactorError(t *testing.T) {
+	sch := NewSchemaWithEngine(NewMemoryEngine())
+	sch.tables = append(sch.tables, &Table{Name: "foo", storage: failingStorage{}})
+
+	c := sch.StartCompactor(time.Millisecond, 0.5)
+	defer c.Stop()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if err := c.Err(); err != nil {
+			if want := "couldn't vacuum table 'foo': disk full"; err.Error() != want {
+				t.Errorf("expected error '%s', but got '%s'", want, err)
+			}
+			return
+		}
+	}
+
+	t.Error("expected the compactor to report the vacuum that failed")
+}
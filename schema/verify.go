@@ -0,0 +1,304 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CorruptionError tells where a file of the database is corrupted.
+type CorruptionError struct {
+	Table  string `json:",omitempty"`
+	File   string
+	Offset int64
+	Reason string
+}
+
+func (e *CorruptionError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("file '%s' is corrupted at offset %d: %s", e.File, e.Offset, e.Reason)
+	}
+
+	return fmt.Sprintf("table '%s' is corrupted at offset %d of file '%s': %s", e.Table, e.Offset, e.File, e.Reason)
+}
+
+// corrupted names the table in the error when it's a corruption of one of its files.
+func (s *fileStorage) corrupted(err error) error {
+	var c *CorruptionError
+	if errors.As(err, &c) && c.Table == "" {
+		c.Table = s.table.Name
+	}
+
+	return err
+}
+
+// Verify checks that the rows of the table and the keys of its indexes can
+// be decoded. It gives every corruption found, errors are only given when
+// the storage can't be read.
+func (t *Table) Verify(ctx context.Context) ([]*CorruptionError, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found, err := t.storage.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range found {
+		c.Table = t.Name
+	}
+
+	return found, nil
+}
+
+// Verify reads every page of the table and of its indexes from their files,
+// checking their checksums and that their rows and nodes can be decoded.
+func (s *fileStorage) Verify(ctx context.Context) ([]*CorruptionError, error) {
+	legacy, err := s.isLegacy()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*CorruptionError
+	if legacy {
+		found, err = verifyLegacyFile(ctx, s.fileName(), s.table.checkRow)
+	} else {
+		found, err = verifyPages(ctx, s.fileName(), s.table.checkRows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, x := range s.table.Indexes {
+		more, err := verifyPages(ctx, s.indexFileName(x), x.checkPage)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, more...)
+	}
+
+	return found, nil
+}
+
+func (t *Table) checkRow(data []byte) error {
+	blobs, err := t.storedColumns(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.deserializeRow(RowID{}, blobs)
+	return err
+}
+
+func (t *Table) checkRows(n uint32, p page) error {
+	if t.Layout == ColumnarLayout {
+		return t.checkGroup(n, p)
+	}
+
+	rows, err := t.pageRows(p)
+	if err != nil {
+		return err
+	}
+
+	for slot, data := range rows {
+		id := RowID{Page: n, Slot: uint16(slot)}
+		if err := t.checkRow(data); err != nil {
+			return fmt.Errorf("row %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func (x *Index) checkPage(n uint32, p page) error {
+	if n == btreeMetaPage {
+		_, err := p.row(0)
+		return err
+	}
+
+	var err error
+	if x.Method == HashIndex {
+		_, err = decodeBucket(p)
+	} else {
+		_, err = decodeNode(p)
+	}
+
+	return err
+}
+
+// Verify checks every table of the schema, along with the data
+// the storage engine keeps for tables the schema doesn't have.
+func (s *Schema) Verify(ctx context.Context) ([]*CorruptionError, error) {
+	s.mu.Lock()
+	tables := slices.Clone(s.tables)
+	s.mu.Unlock()
+
+	var found []*CorruptionError
+	for _, t := range tables {
+		more, err := t.Verify(ctx)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, more...)
+	}
+
+	more, err := s.engine.Verify(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(found, more...), nil
+}
+
+// Verify checks the files of the root directory that belong to tables
+// other than the given ones, which can only have their pages checked.
+func (e *FileEngine) Verify(ctx context.Context, tables []*Table) ([]*CorruptionError, error) {
+	checked := map[string]bool{}
+	for _, t := range tables {
+		if s, ok := t.storage.(*fileStorage); ok {
+			for _, name := range s.fileNames() {
+				checked[name] = true
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(e.rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*CorruptionError
+	for _, entry := range entries {
+		name := path.Join(e.rootDir, entry.Name())
+		if entry.IsDir() || checked[name] || !isDataFile(entry.Name()) {
+			continue
+		}
+
+		more, err := verifyFile(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, more...)
+	}
+
+	return found, nil
+}
+
+// isDataFile tells if the file is a table file, named by the
+// identifier of its table, or the file of one of its indexes.
+func isDataFile(name string) bool {
+	id, rest, _ := strings.Cut(name, ".")
+	if _, err := strconv.ParseUint(id, 10, 32); err != nil {
+		return false
+	}
+
+	return rest == "" || strings.HasSuffix(rest, ".index")
+}
+
+// verifyFile checks the pages of a file whose table isn't known.
+func verifyFile(ctx context.Context, name string) ([]*CorruptionError, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	legacy, err := isLegacyFile(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if legacy {
+		return verifyLegacyFile(ctx, name, nil)
+	}
+
+	return verifyPages(ctx, name, nil)
+}
+
+// verifyPages reads the pages of the file, checking their checksums
+// and structure, and then the page itself with check, when given.
+func verifyPages(ctx context.Context, name string, check func(n uint32, p page) error) ([]*CorruptionError, error) {
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var found []*CorruptionError
+	pages, err := pageCount(file)
+
+	// the whole pages before an incomplete one are still checked
+	var c *CorruptionError
+	if errors.As(err, &c) {
+		found = append(found, c)
+		pages = uint32(c.Offset / PageSize)
+	} else if err != nil {
+		return nil, err
+	}
+
+	p := make(page, PageSize)
+	for n := uint32(0); n < pages; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if _, err := file.ReadAt(p, int64(n)*PageSize); err != nil {
+			return nil, err
+		}
+
+		err := checkPage(name, n, p)
+		if err == nil && check != nil {
+			if err = check(n, p); err != nil {
+				err = &CorruptionError{File: name, Offset: int64(n) * PageSize, Reason: fmt.Sprintf("page %d: %v", n, err)}
+			}
+		}
+
+		if errors.As(err, &c) {
+			found = append(found, c)
+		}
+	}
+
+	return found, nil
+}
+
+// verifyLegacyFile reads the rows of a file in the legacy format, which
+// have no checksums, so only their sizes and their contents are checked.
+func verifyLegacyFile(ctx context.Context, name string, check func(row []byte) error) ([]*CorruptionError, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var found []*CorruptionError
+	err = readLegacyRows(ctx, file, func(row []byte, offset int64) {
+		if check == nil {
+			return
+		}
+
+		if err := check(row); err != nil {
+			found = append(found, &CorruptionError{File: name, Offset: offset, Reason: err.Error()})
+		}
+	})
+
+	// the rows after a corrupted size can't be found
+	var c *CorruptionError
+	if errors.As(err, &c) {
+		return append(found, c), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
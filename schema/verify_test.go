@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVerify(t *testing.T) {
+	table := newTestTable(t)
+
+	for i := 0; i < 300; i++ {
+		if err := table.Insert([]string{strconv.Itoa(i), strings.Repeat("x", 50)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := table.createIndex("test_n", []string{"n"}, BTreeIndex, false); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := table.Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(found) != 0 {
+		t.Errorf("expected no corruption, but got %v", found)
+	}
+
+	file, err := os.OpenFile(fileStorageOf(table).fileName(), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a row of the second page is changed, and half a page is appended
+	if _, err := file.WriteAt([]byte("y"), 2*PageSize-100); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.WriteAt(make([]byte, PageSize/2), 3*PageSize); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	found, err = table.Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int64
+	for _, c := range found {
+		if c.Table != "test" || c.File != fileStorageOf(table).fileName() {
+			t.Errorf("expected the corruption to be in table 'test', but got %v", c)
+		}
+		got = append(got, c.Offset)
+	}
+
+	if diff := cmp.Diff([]int64{3 * PageSize, PageSize}, got); diff != "" {
+		t.Errorf("offsets of the corruption (-want +got):\n%s", diff)
+	}
+
+	// files with an incomplete page can't be opened by the pool, whose
+	// pages were cached before the corruption, so it's replaced
+	if err := os.Truncate(fileStorageOf(table).fileName(), 3*PageSize); err != nil {
+		t.Fatal(err)
+	}
+	fileStorageOf(table).pool = NewBufferPool(8)
+	defer fileStorageOf(table).pool.Close()
+
+	_, err = table.Fetch(RowID{Page: 1, Slot: 0})
+	want := "table 'test' is corrupted at offset 8192 of file '" + fileStorageOf(table).fileName() + "': page 1 has checksum"
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expected error '%s...', but got %v", want, err)
+	}
+}
+
+func TestVerifyLegacyFile(t *testing.T) {
+	table := newTestTable(t)
+
+	row, err := serializeValues(table, []string{"1", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(row)))
+	data = append(data, row...)
+	data = binary.LittleEndian.AppendUint32(data, 1000)
+	data = append(data, row...)
+	if err := os.WriteFile(fileStorageOf(table).fileName(), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := table.Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*CorruptionError{{
+		Table:  "test",
+		File:   fileStorageOf(table).fileName(),
+		Offset: int64(4 + len(row)),
+		Reason: "row of 1000 bytes goes past the end of the file",
+	}}
+	if diff := cmp.Diff(want, found); diff != "" {
+		t.Errorf("corruption of the legacy file (-want +got):\n%s", diff)
+	}
+
+	err = table.Read(context.Background(), &strings.Builder{}, nil, func(*DeserializedRow) (bool, error) {
+		return false, nil
+	})
+	if err == nil || err.Error() != want[0].Error() {
+		t.Errorf("expected error '%s', but got %v", want[0], err)
+	}
+}
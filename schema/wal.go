@@ -0,0 +1,305 @@
+package schema
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// WALFileName is the name of the write-ahead log in the root directory.
+	WALFileName = "wal"
+
+	// DefaultCheckpointSize is the size the log can grow to before
+	// a checkpoint empties it, unless it's given another one.
+	DefaultCheckpointSize = 16 << 20
+
+	walPageRecord   = 1
+	walCommitRecord = 2
+
+	// size prefix and checksum of a record
+	walRecordOverhead = 8
+)
+
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WAL is a write-ahead log, every page changed by a transaction is appended
+// to it, followed by a commit record, and it's synced before the pages are
+// written to their files. A crash while writing them leaves the pages in the
+// log, which are written again when it's opened.
+//
+// Records are prefixed by their size and followed by their checksum, page
+// records hold the file, relative to the log's directory when it's in it,
+// the page number and the page. A record that was only partly written when
+// a crash happened fails its checksum, it's discarded along with the pages
+// of its transaction, which was never committed.
+type WAL struct {
+	mu   sync.Mutex
+	dir  string
+	file *os.File
+	size int64
+
+	// CheckpointSize is the size the log can grow to before a
+	// checkpoint syncs the files written so far and empties it.
+	CheckpointSize int64
+}
+
+type walPage struct {
+	file string
+	n    uint32
+	data page
+}
+
+// OpenWAL opens the write-ahead log of the directory, creating it when it
+// doesn't exist. The pages of every transaction committed to it are written
+// to their files, the ones that weren't committed are discarded.
+func OpenWAL(dir string) (*WAL, error) {
+	file, err := os.OpenFile(path.Join(dir, WALFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WAL{dir: dir, file: file, CheckpointSize: DefaultCheckpointSize}
+	if err := w.recover(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("couldn't recover the write-ahead log: %w", err)
+	}
+
+	return w, nil
+}
+
+// recover replays the committed transactions, then empties the log.
+func (w *WAL) recover() error {
+	data, err := io.ReadAll(w.file)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]*os.File{}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	for _, pages := range w.committed(data) {
+		for _, p := range pages {
+			if err := w.replay(files, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range files {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+
+	return w.checkpoint(func() error { return nil })
+}
+
+// committed gives the pages of every transaction committed to the log,
+// the records after the first one that can't be decoded are discarded.
+func (w *WAL) committed(data []byte) [][]walPage {
+	var txs [][]walPage
+	var pending []walPage
+	for len(data) > 0 {
+		record, rest, ok := decodeWALRecord(data)
+		if !ok {
+			break
+		}
+		data = rest
+
+		if record[0] == walCommitRecord {
+			txs = append(txs, pending)
+			pending = nil
+			continue
+		}
+
+		p, ok := w.decodePage(record)
+		if !ok {
+			break
+		}
+		pending = append(pending, p)
+	}
+
+	return txs
+}
+
+// PendingTransactions counts the transactions committed to the log of the
+// directory that a checkpoint hasn't emptied yet, without changing it. The
+// pages of those may not have reached their files, which only happens once
+// the log is opened again.
+func PendingTransactions(dir string) (int, error) {
+	data, err := os.ReadFile(path.Join(dir, WALFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	w := &WAL{dir: dir}
+	return len(w.committed(data)), nil
+}
+
+// replay writes the page to its file, filling the pages before it
+// with empty ones when the file ends before them.
+func (w *WAL) replay(files map[string]*os.File, p walPage) error {
+	f, ok := files[p.file]
+	if !ok {
+		var err error
+		if f, err = os.OpenFile(p.file, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+			return err
+		}
+		files[p.file] = f
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// a page that was only partly appended is written over
+	for pages := uint32(info.Size() / PageSize); pages < p.n; pages++ {
+		if err := writePage(f, pages, newPage()); err != nil {
+			return err
+		}
+	}
+
+	return writePage(f, p.n, p.data)
+}
+
+// commit appends the pages of a transaction to the log and syncs it,
+// then writes them with write. It reports when the log is large enough
+// to need a checkpoint.
+func (w *WAL) commit(frames []*frame, write func() error) (bool, error) {
+	var buf []byte
+	for _, fr := range frames {
+		fr.latch.RLock()
+		buf = appendWALRecord(buf, w.encodePage(fr.key.file, fr.key.page, fr.data))
+		fr.latch.RUnlock()
+	}
+	buf = appendWALRecord(buf, []byte{walCommitRecord})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// records are written at the end of the ones known to be whole,
+	// so a failed write never leaves a torn record before later ones
+	if _, err := w.file.WriteAt(buf, w.size); err != nil {
+		w.file.Truncate(w.size)
+		return false, err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		w.file.Truncate(w.size)
+		return false, err
+	}
+	w.size += int64(len(buf))
+
+	if err := write(); err != nil {
+		return false, err
+	}
+
+	return w.size >= w.CheckpointSize, nil
+}
+
+// checkpoint empties the log once sync has made sure
+// that every page written so far is on disk.
+func (w *WAL) checkpoint(sync func() error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := sync(); err != nil {
+		return err
+	}
+
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	w.size = 0
+
+	return nil
+}
+
+func (w *WAL) close() error {
+	return w.file.Close()
+}
+
+func (w *WAL) encodePage(file string, n uint32, data page) []byte {
+	if rel, err := filepath.Rel(w.dir, file); err == nil && filepath.IsLocal(rel) {
+		file = rel
+	} else if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+
+	record := []byte{walPageRecord}
+	record = binary.LittleEndian.AppendUint16(record, uint16(len(file)))
+	record = append(record, file...)
+	record = binary.LittleEndian.AppendUint32(record, n)
+
+	return append(record, data...)
+}
+
+func (w *WAL) decodePage(record []byte) (walPage, bool) {
+	if record[0] != walPageRecord || len(record) < 3 {
+		return walPage{}, false
+	}
+
+	size := int(binary.LittleEndian.Uint16(record[1:]))
+	record = record[3:]
+	if len(record) != size+4+PageSize {
+		return walPage{}, false
+	}
+
+	p := walPage{
+		file: string(record[:size]),
+		n:    binary.LittleEndian.Uint32(record[size:]),
+		data: page(record[size+4:]),
+	}
+
+	if !filepath.IsAbs(p.file) {
+		p.file = path.Join(w.dir, p.file)
+	}
+
+	return p, true
+}
+
+func appendWALRecord(buf, record []byte) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(record)))
+	buf = append(buf, record...)
+
+	return binary.LittleEndian.AppendUint32(buf, crc32.Checksum(record, walTable))
+}
+
+// decodeWALRecord gives the first record of data and what follows it,
+// it reports false when the record is incomplete or corrupted.
+func decodeWALRecord(data []byte) ([]byte, []byte, bool) {
+	if len(data) < walRecordOverhead {
+		return nil, nil, false
+	}
+
+	size := binary.LittleEndian.Uint32(data)
+	if size == 0 || uint64(size) > uint64(len(data)-walRecordOverhead) {
+		return nil, nil, false
+	}
+
+	record := data[4 : 4+size]
+	if binary.LittleEndian.Uint32(data[4+size:]) != crc32.Checksum(record, walTable) {
+		return nil, nil, false
+	}
+
+	return record, data[4+size+4:], true
+}
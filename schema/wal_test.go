@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newWALTable(t *testing.T, dir string) *Table {
+	w, err := OpenWAL(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := newTestTable(t)
+	fileStorageOf(table).rootDir = dir
+	fileStorageOf(table).pool = NewBufferPool(8)
+	fileStorageOf(table).pool.SetWAL(w)
+
+	return table
+}
+
+func TestWALRecovery(t *testing.T) {
+	dir := t.TempDir()
+	table := newWALTable(t, dir)
+
+	const rows = 300
+	for i := 0; i < rows; i++ {
+		if err := table.Insert([]string{strconv.Itoa(i), strings.Repeat("x", 50)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the writes to the table file are lost by a crash,
+	// after a transaction was only partly logged
+	if err := os.Truncate(fileStorageOf(table).fileName(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, WALFileName), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uncommitted := appendWALRecord(nil, fileStorageOf(table).pool.wal.encodePage(fileStorageOf(table).fileName(), 0, newPage()))
+	torn := appendWALRecord(nil, []byte{walCommitRecord})
+	if _, err := file.Write(append(uncommitted, torn[:len(torn)-1]...)); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	recovered := newWALTable(t, dir)
+	defer fileStorageOf(recovered).pool.Close()
+
+	if got := readAll(t, recovered); len(got) != rows {
+		t.Errorf("expected %d rows to be recovered, but got %d", rows, len(got))
+	}
+
+	info, err := os.Stat(filepath.Join(dir, WALFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Size() != 0 {
+		t.Errorf("expected the log to be emptied once recovered, but it has %d bytes", info.Size())
+	}
+}
+
+func TestWALCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	table := newWALTable(t, dir)
+	defer fileStorageOf(table).pool.Close()
+
+	fileStorageOf(table).pool.wal.CheckpointSize = 4 * PageSize
+
+	for i := 0; i < 10; i++ {
+		if err := table.Insert([]string{strconv.Itoa(i), "row"}); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, WALFileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Size() >= fileStorageOf(table).pool.wal.CheckpointSize {
+			t.Errorf("expected a checkpoint to empty the log, but it has %d bytes after %d rows", info.Size(), i+1)
+		}
+	}
+}
+
+func TestTransactionAbort(t *testing.T) {
+	table := newWALTable(t, t.TempDir())
+	defer fileStorageOf(table).pool.Close()
+
+	if _, err := table.createIndex("test_s", []string{"s"}, BTreeIndex, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Insert([]string{"1", "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the row fits in a page, but its key doesn't fit in the
+	// index, so writing the row is undone along with it
+	if err := table.Insert([]string{"2", strings.Repeat("x", MaxIndexKeySize)}); err == nil {
+		t.Error("expected a key too large for the index to be rejected")
+	}
+
+	if rows := readAll(t, table); len(rows) != 1 {
+		t.Errorf("expected the rejected row not to be stored, but got %d rows", len(rows))
+	}
+}
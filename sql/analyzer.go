@@ -0,0 +1,363 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/jvitoroc/gobase/eval"
+	"github.com/jvitoroc/gobase/schema"
+)
+
+type analyzer struct {
+	schema *schema.Schema
+
+	// tables, types and indexes created by previous statements of the batch
+	created        map[string][]*schema.Column
+	createdTypes   map[string]*eval.EnumType
+	createdIndexes map[string]bool
+}
+
+// Analyze resolves every table and column the statements refer to against
+// the schema and checks the types of their expressions, so invalid statements
+// are rejected before any of them is executed. Statements are analyzed in
+// order, which makes tables created in the batch visible to the ones after.
+func Analyze(sts []*Statement, sch *schema.Schema) error {
+	a := &analyzer{
+		schema:         sch,
+		created:        map[string][]*schema.Column{},
+		createdTypes:   map[string]*eval.EnumType{},
+		createdIndexes: map[string]bool{},
+	}
+
+	for i, s := range sts {
+		if err := a.statement(s); err != nil {
+			return fmt.Errorf("statement #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) statement(s *Statement) error {
+	if len(s.Clauses) == 0 {
+		return nil
+	}
+
+	switch s.Clauses[0].Type {
+	case CreateTable:
+		return a.createTable(s)
+	case CreateType:
+		return a.createType(s)
+	case CreateIndex, CreateUniqueIndex:
+		return a.createIndex(s)
+	case InsertInto:
+		return a.insertInto(s)
+	case Select:
+		return a.selectStatement(s)
+	case VerifyTable:
+		return a.verifyTable(s)
+	case Vacuum:
+		return a.vacuum(s)
+	}
+
+	return nil
+}
+
+func (a *analyzer) createTable(s *Statement) error {
+	tableName := ""
+	var columns []*schema.Column
+
+	for _, c := range s.Clauses {
+		switch c.Type {
+		case CreateTable:
+			tableName, _ = c.Body.(string)
+		case Definitions:
+			defs, _ := c.Body.([]*schema.NewColumn)
+			for _, d := range defs {
+				c := &schema.Column{Name: d.Name, Type: d.Type, Precision: d.Precision, Scale: d.Scale, Length: d.Length}
+				if d.Enum != "" {
+					t, err := a.enumType(d.Enum)
+					if err != nil {
+						return err
+					}
+					c.Enum = t
+				}
+
+				columns = append(columns, c)
+			}
+		}
+	}
+
+	if _, err := a.table(tableName); err == nil {
+		return fmt.Errorf("table with name '%s' already exists", tableName)
+	}
+
+	seen := map[string]bool{}
+	for _, c := range columns {
+		if seen[c.Name] {
+			return fmt.Errorf("column '%s' is defined more than once", c.Name)
+		}
+		seen[c.Name] = true
+
+		if err := c.CheckDefinition(); err != nil {
+			return err
+		}
+	}
+
+	a.created[tableName] = columns
+
+	return nil
+}
+
+func (a *analyzer) createType(s *Statement) error {
+	typeName := ""
+	var labels []string
+
+	for _, c := range s.Clauses {
+		switch c.Type {
+		case CreateType:
+			typeName, _ = c.Body.(string)
+		case AsEnum:
+			labels, _ = c.Body.([]string)
+		}
+	}
+
+	if _, err := a.enumType(typeName); err == nil {
+		return fmt.Errorf("type with name '%s' already exists", typeName)
+	}
+
+	if err := schema.CheckEnumLabels(labels); err != nil {
+		return err
+	}
+
+	a.createdTypes[typeName] = &eval.EnumType{Name: typeName, Labels: labels}
+
+	return nil
+}
+
+func (a *analyzer) createIndex(s *Statement) error {
+	indexName := ""
+	on := &IndexOn{}
+
+	for _, c := range s.Clauses {
+		switch c.Type {
+		case CreateIndex, CreateUniqueIndex:
+			indexName, _ = c.Body.(string)
+		case On:
+			on, _ = c.Body.(*IndexOn)
+		}
+	}
+
+	if a.createdIndexes[indexName] || a.schema != nil && a.schema.GetIndex(indexName) != nil {
+		return fmt.Errorf("index with name '%s' already exists", indexName)
+	}
+
+	columns, err := a.table(on.Table)
+	if err != nil {
+		return err
+	}
+
+	if _, err := schema.IndexColumns(on.Table, columns, on.Columns); err != nil {
+		return err
+	}
+
+	a.createdIndexes[indexName] = true
+
+	return nil
+}
+
+func (a *analyzer) verifyTable(s *Statement) error {
+	tableName, _ := s.Clauses[0].Body.(string)
+	_, err := a.table(tableName)
+
+	return err
+}
+
+// vacuum checks the table of VACUUM, which vacuums every table without one.
+func (a *analyzer) vacuum(s *Statement) error {
+	tableName, _ := s.Clauses[0].Body.(string)
+	if tableName == "" {
+		return nil
+	}
+
+	_, err := a.table(tableName)
+
+	return err
+}
+
+func (a *analyzer) insertInto(s *Statement) error {
+	tableName := ""
+	var values []*eval.Expression
+
+	for _, c := range s.Clauses {
+		switch c.Type {
+		case InsertInto:
+			tableName, _ = c.Body.(string)
+		case Values:
+			values, _ = c.Body.([]*eval.Expression)
+		}
+	}
+
+	columns, err := a.table(tableName)
+	if err != nil {
+		return err
+	}
+
+	if len(columns) != len(values) {
+		return fmt.Errorf("table has %d columns, but %d values were given", len(columns), len(values))
+	}
+
+	for i, c := range columns {
+		// literals are given as text to the column, which decides
+		// if they are valid, that way a string can be inserted in
+		// a date column, for instance
+		v := eval.Optimize(values[i])
+		if v.IsLiteral() {
+			if err := c.CheckValue(schema.FormatValue(v.GoValue)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// there's no row yet, so values can't refer to any column
+		got, err := eval.InferType(v, nil)
+		if err != nil {
+			return err
+		}
+
+		want, err := columnValueType(c)
+		if err != nil {
+			return err
+		}
+
+		// strings are checked against the labels when they are inserted
+		if want == eval.EnumValue && got == eval.StringValue {
+			continue
+		}
+
+		// only the day of a timestamp is kept in a date column
+		if want == eval.DateValue && got == eval.TimestampValue {
+			continue
+		}
+
+		if got != want {
+			return fmt.Errorf("column '%s' data type is %s, but the value at %d:%d results in '%s'", c.Name, c.TypeName(), v.Line, v.Column, got)
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) selectStatement(s *Statement) error {
+	tableName := ""
+	for _, c := range s.Clauses {
+		if c.Type == From {
+			tableName, _ = c.Body.(string)
+		}
+	}
+
+	columns, err := a.table(tableName)
+	if err != nil {
+		return err
+	}
+
+	types := make(map[string]eval.ValueType, len(columns))
+	for _, c := range columns {
+		t, err := columnValueType(c)
+		if err != nil {
+			return err
+		}
+		types[c.Name] = t
+	}
+
+	for _, c := range s.Clauses {
+		switch c.Type {
+		case Select:
+			exprs, _ := c.Body.([]*eval.Expression)
+			for _, e := range exprs {
+				if _, err := eval.InferType(e, types); err != nil {
+					return err
+				}
+			}
+		case Where:
+			e, _ := c.Body.(*eval.Expression)
+			if e == nil {
+				continue
+			}
+
+			t, err := eval.InferType(e, types)
+			if err != nil {
+				return err
+			}
+
+			if t != eval.BoolValue {
+				return fmt.Errorf("WHERE clause must result in a boolean value, but got '%s' at %d:%d", t, e.Line, e.Column)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) table(name string) ([]*schema.Column, error) {
+	if columns, ok := a.created[name]; ok {
+		return columns, nil
+	}
+
+	if a.schema != nil {
+		if t := a.schema.GetTable(name); t != nil {
+			return t.Columns, nil
+		}
+	}
+
+	return nil, fmt.Errorf("table with name '%s' does not exist", name)
+}
+
+func (a *analyzer) enumType(name string) (*eval.EnumType, error) {
+	if t, ok := a.createdTypes[name]; ok {
+		return t, nil
+	}
+
+	if a.schema != nil {
+		if t := a.schema.GetType(name); t != nil {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("type with name '%s' does not exist", name)
+}
+
+func columnValueType(c *schema.Column) (eval.ValueType, error) {
+	if c.IsArray() {
+		t, err := columnValueType(c.Element())
+		if err != nil {
+			return "", err
+		}
+
+		return eval.ArrayOf(t), nil
+	}
+
+	switch c.Type {
+	case schema.Int32Type, schema.Int64Type, schema.FloatType, schema.DecimalType:
+		return eval.NumberValue, nil
+	case schema.StringType, schema.VarcharType, schema.CharType:
+		return eval.StringValue, nil
+	case schema.BoolType:
+		return eval.BoolValue, nil
+	case schema.BytesType:
+		return eval.BytesValue, nil
+	case schema.UUIDType:
+		return eval.UUIDValue, nil
+	case schema.JSONType:
+		return eval.JSONValue, nil
+	case schema.EnumType:
+		return eval.EnumValue, nil
+	case schema.DateType:
+		return eval.DateValue, nil
+	case schema.TimeType:
+		return eval.TimeValue, nil
+	case schema.TimestampType:
+		return eval.TimestampValue, nil
+	}
+
+	return "", fmt.Errorf("column '%s' has unsupported type '%s'", c.Name, c.Type)
+}
@@ -0,0 +1,183 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/jvitoroc/gobase/schema"
+)
+
+func TestAnalyze(t *testing.T) {
+	sch := schema.NewSchemaWithEngine(schema.NewMemoryEngine())
+	_, err := sch.CreateTable("foo", []*schema.NewColumn{
+		{Name: "foo", Type: schema.BoolType},
+		{Name: "bar", Type: schema.Int32Type},
+		{Name: "baz", Type: schema.StringType},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	type test struct {
+		input       string
+		expectedErr string
+	}
+	tests := []test{
+		{
+			input: `SELECT foo, bar FROM foo WHERE foo != false AND bar > 100 AND baz >= "m";`,
+		},
+		{
+			input: `SELECT CASE WHEN bar > 10 THEN "big" ELSE "small" END FROM foo WHERE CASE baz WHEN "a" THEN true ELSE foo END;`,
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (a int);
+				INSERT INTO bar VALUES (1);
+				SELECT a FROM bar WHERE a == 1;
+			`,
+		},
+		{
+			input:       `SELECT foo FROM bar;`,
+			expectedErr: "statement #1: table with name 'bar' does not exist",
+		},
+		{
+			input:       `SELECT foo FROM foo WHERE qux == 1;`,
+			expectedErr: "statement #1: column 'qux' does not exist at 1:27",
+		},
+		{
+			input: `
+				SELECT foo FROM foo;
+				SELECT foo FROM foo WHERE
+					bar == "123";`,
+			expectedErr: "statement #2: both sides of a comparison operation must be of the same type, but got 'number' and 'string' at 4:10",
+		},
+		{
+			input:       `SELECT foo FROM foo WHERE bar;`,
+			expectedErr: "statement #1: WHERE clause must result in a boolean value, but got 'number' at 1:27",
+		},
+		{
+			input:       `SELECT foo FROM foo WHERE foo AND bar;`,
+			expectedErr: "statement #1: both sides of a logical operation must be boolean values, but got 'bool' and 'number' at 1:31",
+		},
+		{
+			input:       `SELECT qux FROM foo;`,
+			expectedErr: "statement #1: column 'qux' does not exist at 1:8",
+		},
+		{
+			input:       `SELECT CASE WHEN foo THEN 1 ELSE "1" END FROM foo;`,
+			expectedErr: "statement #1: all results of a CASE expression must be of the same type, expected 'number', but got 'string' at 1:34",
+		},
+		{
+			input:       `SELECT CASE bar WHEN "1" THEN 1 END FROM foo;`,
+			expectedErr: "statement #1: WHEN value of a CASE expression must be of type 'number', but got 'string' at 1:22",
+		},
+		{
+			input:       `INSERT INTO foo VALUES (true, 1);`,
+			expectedErr: "statement #1: table has 3 columns, but 2 values were given",
+		},
+		{
+			input:       `INSERT INTO foo VALUES (true, "a", "b");`,
+			expectedErr: "statement #1: column 'bar' data type is int, value 'a' is invalid for this column",
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (id uuid, n int, at timestamp);
+				INSERT INTO bar VALUES (GEN_RANDOM_UUID(), 2 * 3, NOW());
+				INSERT INTO bar VALUES (UUID "0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10", 1, "2024-01-31");
+				INSERT INTO bar VALUES ("0190a5e0-7c4b-7cc2-9a35-4c2f7a0b8f10", 1, DATE "2024-01-31");
+			`,
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (id uuid);
+				INSERT INTO bar VALUES (UUIDV7() == UUIDV7());
+			`,
+			expectedErr: "statement #2: column 'id' data type is uuid, but the value at 3:38 results in 'bool'",
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (id uuid);
+				INSERT INTO bar VALUES ("not-a-uuid");
+			`,
+			expectedErr: "statement #2: column 'id' data type is uuid, value 'not-a-uuid' is invalid for this column",
+		},
+		{
+			input:       `INSERT INTO foo VALUES (true, bar, "b");`,
+			expectedErr: "statement #1: column 'bar' does not exist at 1:31",
+		},
+		{
+			input:       `CREATE TABLE foo DEFINITIONS (a int);`,
+			expectedErr: "statement #1: table with name 'foo' already exists",
+		},
+		{
+			input:       `CREATE TABLE bar DEFINITIONS (a int, a string);`,
+			expectedErr: "statement #1: column 'a' is defined more than once",
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (a int);
+				SELECT a FROM bar WHERE b == 1;
+			`,
+			expectedErr: "statement #2: column 'b' does not exist at 3:29",
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (a int, b string);
+				CREATE UNIQUE INDEX bar_a_b ON bar (a, b);
+			`,
+		},
+		{
+			input: `
+				CREATE INDEX foo_bar ON foo (bar);
+				CREATE INDEX foo_bar ON foo (baz);
+			`,
+			expectedErr: "statement #2: index with name 'foo_bar' already exists",
+		},
+		{
+			input:       `CREATE INDEX foo_qux ON foo (qux);`,
+			expectedErr: "statement #1: column 'qux' does not exist in table 'foo'",
+		},
+		{
+			input:       `CREATE INDEX bar_a ON bar (a);`,
+			expectedErr: "statement #1: table with name 'bar' does not exist",
+		},
+		{
+			input: `
+				CREATE TABLE bar DEFINITIONS (date date, time time);
+				SELECT date FROM bar WHERE date > DATE "2024-01-01" AND time < TIME "12:00:00";
+			`,
+		},
+		{
+			input: `VERIFY TABLE foo;`,
+		},
+		{
+			input:       `verify table bar;`,
+			expectedErr: "statement #1: table with name 'bar' does not exist",
+		},
+		{
+			input: `VACUUM; VACUUM foo;`,
+		},
+		{
+			input:       `VACUUM bar;`,
+			expectedErr: "statement #1: table with name 'bar' does not exist",
+		},
+	}
+
+	for i, tt := range tests {
+		sts, err := NewParser(tt.input).Parse()
+		if err != nil {
+			t.Errorf("test %d failed: %s", i+1, err)
+			continue
+		}
+
+		err = Analyze(sts, sch)
+		gotErr := ""
+		if err != nil {
+			gotErr = err.Error()
+		}
+
+		if tt.expectedErr != gotErr {
+			t.Errorf("test %d failed: expected err '%s', but got '%s'", i+1, tt.expectedErr, gotErr)
+		}
+	}
+}
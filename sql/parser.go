@@ -17,11 +17,52 @@ const (
 
 	CreateTable ClauseType = "create table"
 	Definitions ClauseType = "definitions"
+	With        ClauseType = "with"
 
 	InsertInto ClauseType = "insert into"
 	Values     ClauseType = "values"
+
+	CreateType ClauseType = "create type"
+	AsEnum     ClauseType = "as enum"
+
+	CreateIndex       ClauseType = "create index"
+	CreateUniqueIndex ClauseType = "create unique index"
+	On                ClauseType = "on"
+
+	VerifyTable ClauseType = "verify table"
+	Vacuum      ClauseType = "vacuum"
 )
 
+// dataTypeNames maps the names accepted for the built-in column types to
+// the type they stand for. Apart from the ones the tokenizer reserves, the
+// names are only told apart from identifiers in type position, so they can
+// still name columns, any other name there is a type created by the user.
+var dataTypeNames = map[string]schema.ColumnDataType{
+	"int":       schema.Int32Type,
+	"bigint":    schema.Int64Type,
+	"float":     schema.FloatType,
+	"double":    schema.FloatType,
+	"string":    schema.StringType,
+	"varchar":   schema.VarcharType,
+	"char":      schema.CharType,
+	"bool":      schema.BoolType,
+	"date":      schema.DateType,
+	"time":      schema.TimeType,
+	"timestamp": schema.TimestampType,
+	"decimal":   schema.DecimalType,
+	"numeric":   schema.DecimalType,
+	"bytes":     schema.BytesType,
+	"uuid":      schema.UUIDType,
+	"json":      schema.JSONType,
+}
+
+// IndexOn is the body of the ON clause of CREATE INDEX.
+type IndexOn struct {
+	Table   string
+	Using   schema.IndexMethod
+	Columns []string
+}
+
 type Clause struct {
 	Type ClauseType
 	Body any
@@ -77,6 +118,10 @@ func (p *parser) Statements() ([]*Statement, error) {
 				break
 			}
 
+			if p.indexOn(s) {
+				p.lookahead._type = clause
+			}
+
 			clause, err := p.clause()
 			if err != nil {
 				return nil, err
@@ -91,6 +136,17 @@ func (p *parser) Statements() ([]*Statement, error) {
 	return sts, nil
 }
 
+// indexOn tells if the next token is the ON of CREATE INDEX, ON isn't
+// reserved by the tokenizer, so it can still be used as a name anywhere else.
+func (p *parser) indexOn(s *Statement) bool {
+	if p.lookahead._type != identifier || p.lookahead.strValue != string(On) || len(s.Clauses) == 0 {
+		return false
+	}
+
+	last := s.Clauses[len(s.Clauses)-1].Type
+	return last == CreateIndex || last == CreateUniqueIndex
+}
+
 func (p *parser) clause() (*Clause, error) {
 	if p.lookahead._type != clause {
 		return nil, fmt.Errorf("expected clause keyword, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
@@ -126,10 +182,24 @@ func (p *parser) clauseBody(_type ClauseType, tk token) (any, error) {
 		return p.identifier()
 	case Definitions:
 		return p.definitionsBody()
+	case With:
+		return p.withBody()
 	case InsertInto:
 		return p.identifier()
 	case Values:
 		return p.valuesBody()
+	case CreateType:
+		return p.identifier()
+	case AsEnum:
+		return p.labelsBody()
+	case CreateIndex, CreateUniqueIndex:
+		return p.identifier()
+	case On:
+		return p.onBody()
+	case VerifyTable:
+		return p.identifier()
+	case Vacuum:
+		return p.vacuumBody()
 	}
 
 	return nil, fmt.Errorf("clause '%s' not supported at %d:%d", _type, tk.line, tk.column)
@@ -141,18 +211,9 @@ func (p *parser) selectBody() (any, error) {
 	var lastComma token
 
 	for {
-		tempTokens := make([]token, 0)
-		for {
-			if !p.lookahead.isPredicateToken() {
-				break
-			}
-
-			tempTokens = append(tempTokens, p.lookahead)
-
-			err := p.moveToNextToken()
-			if err != nil {
-				return nil, err
-			}
+		tempTokens, err := p.expressionTokens(false)
+		if err != nil {
+			return nil, err
 		}
 
 		if len(tempTokens) == 0 && lastComma != tokenNoop {
@@ -163,6 +224,14 @@ func (p *parser) selectBody() (any, error) {
 			return nil, fmt.Errorf("invalid eval.Expression at %d:%d", p.validLine(), p.validColumn())
 		}
 
+		if err := checkParenthesesBalance(tempTokens); err != nil {
+			return nil, err
+		}
+
+		if err := checkBooleanExpressionSyntax(tempTokens); err != nil {
+			return nil, err
+		}
+
 		expr, err := infixToExpressionTree(tempTokens)
 		if err != nil {
 			return nil, err
@@ -171,7 +240,6 @@ func (p *parser) selectBody() (any, error) {
 		body = append(body, expr)
 
 		if p.lookahead._type == comma {
-			var err error
 			lastComma, err = p.consume()
 			if err != nil {
 				return nil, err
@@ -216,7 +284,7 @@ func (p *parser) definitionsBody() (any, error) {
 
 		c.Name = tk.strValue
 
-		if p.lookahead._type != dataType {
+		if p.lookahead._type != dataType && p.lookahead._type != identifier {
 			return nil, fmt.Errorf("expected column type, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
 		}
 
@@ -226,6 +294,53 @@ func (p *parser) definitionsBody() (any, error) {
 		}
 
 		c.Type = schema.ColumnDataType(tk.strValue)
+		if t, ok := dataTypeNames[tk.strValue]; ok {
+			c.Type = t
+		} else if tk._type == identifier {
+			c.Type = schema.EnumType
+			c.Enum = tk.strValue
+		}
+
+		modifiers, err := p.typeModifiers()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case c.Type == schema.DecimalType && len(modifiers) == 0:
+			return nil, fmt.Errorf("type '%s' requires a precision at %d:%d", tk.strValue, tk.line, tk.column)
+		case c.Type == schema.DecimalType && len(modifiers) <= 2:
+			c.Precision = modifiers[0]
+			if len(modifiers) > 1 {
+				c.Scale = modifiers[1]
+			}
+		case c.Type == schema.VarcharType && len(modifiers) == 0:
+			return nil, fmt.Errorf("type '%s' requires a length at %d:%d", tk.strValue, tk.line, tk.column)
+		case (c.Type == schema.VarcharType || c.Type == schema.CharType) && len(modifiers) <= 1:
+			// a char without length holds a single character
+			c.Length = 1
+			if len(modifiers) > 0 {
+				c.Length = modifiers[0]
+			}
+		case len(modifiers) > 0:
+			return nil, fmt.Errorf("too many modifiers for type '%s' at %d:%d", tk.strValue, tk.line, tk.column)
+		}
+
+		if p.lookahead._type == leftBracket {
+			if _, err := p.consume(); err != nil {
+				return nil, err
+			}
+
+			if p.lookahead._type != rightBracket {
+				return nil, fmt.Errorf("expected closing bracket, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+			}
+
+			if _, err := p.consume(); err != nil {
+				return nil, err
+			}
+
+			c.Type = schema.ArrayOf(c.Type)
+		}
 
 		def = append(def, c)
 
@@ -255,8 +370,166 @@ func (p *parser) definitionsBody() (any, error) {
 	return def, nil
 }
 
+// typeModifiers parses the integers that may follow a column
+// type between parentheses, such as in DECIMAL(10, 2).
+func (p *parser) typeModifiers() ([]int, error) {
+	if !p.lookahead.isLeftParenthesis() {
+		return nil, nil
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	modifiers := []int{}
+	for {
+		v, ok := p.lookahead.goValue.(int64)
+		if p.lookahead._type != numberLiteral || !ok {
+			return nil, fmt.Errorf("expected integer type modifier, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		modifiers = append(modifiers, int(v))
+
+		if p.lookahead._type != comma {
+			break
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.lookahead.isRightParenthesis() {
+		return nil, fmt.Errorf("expected closing parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	return modifiers, nil
+}
+
+// labelsBody parses the parenthesized string literals of an enum.
+func (p *parser) labelsBody() (any, error) {
+	labels := []string{}
+
+	if !p.lookahead.isLeftParenthesis() {
+		return nil, fmt.Errorf("expected opening parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.lookahead._type != stringLiteral {
+			return nil, fmt.Errorf("expected enum label, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		tk, err := p.consume()
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, tk.strValue)
+
+		if p.lookahead._type != comma {
+			break
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.lookahead.isRightParenthesis() {
+		return nil, fmt.Errorf("expected closing parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// onBody parses the table of an index, optionally followed by USING and the
+// method of the index, and then by its parenthesized columns.
+func (p *parser) onBody() (any, error) {
+	table, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	on := &IndexOn{Table: table.(string), Using: schema.BTreeIndex}
+
+	if p.lookahead._type == identifier && p.lookahead.strValue == "using" {
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		if p.lookahead._type != identifier {
+			return nil, fmt.Errorf("expected index method, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		tk, err := p.consume()
+		if err != nil {
+			return nil, err
+		}
+
+		on.Using = schema.IndexMethod(tk.strValue)
+		if on.Using != schema.BTreeIndex && on.Using != schema.HashIndex {
+			return nil, fmt.Errorf("index method '%s' does not exist at %d:%d", tk.strValue, tk.line, tk.column)
+		}
+	}
+
+	if !p.lookahead.isLeftParenthesis() {
+		return nil, fmt.Errorf("expected opening parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.lookahead._type != identifier {
+			return nil, fmt.Errorf("expected column name, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		tk, err := p.consume()
+		if err != nil {
+			return nil, err
+		}
+
+		on.Columns = append(on.Columns, tk.strValue)
+
+		if p.lookahead._type != comma {
+			break
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.lookahead.isRightParenthesis() {
+		return nil, fmt.Errorf("expected closing parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	return on, nil
+}
+
 func (p *parser) valuesBody() (any, error) {
-	values := []string{}
+	values := []*eval.Expression{}
 
 	if !p.lookahead.isLeftParenthesis() {
 		return nil, fmt.Errorf("expected opening parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
@@ -272,16 +545,12 @@ func (p *parser) valuesBody() (any, error) {
 			break
 		}
 
-		if !p.lookahead.isLiteral() {
-			return nil, fmt.Errorf("expected literal, but got '%s'", p.lookahead.strValue)
-		}
-
-		tk, err := p.consume()
+		v, err := p.expression("VALUES", true)
 		if err != nil {
 			return nil, err
 		}
 
-		values = append(values, tk.strValue)
+		values = append(values, v)
 
 		if p.lookahead.isRightParenthesis() {
 			err := p.moveToNextToken()
@@ -310,33 +579,492 @@ func (p *parser) valuesBody() (any, error) {
 }
 
 func (p *parser) whereBody() (any, error) {
-	body := make([]token, 0)
+	body, err := p.expressionTokens(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) == 0 {
+		return nil, errors.New("expected predicate after 'WHERE', but got nothing")
+	}
+
+	if err := checkParenthesesBalance(body); err != nil {
+		return nil, err
+	}
+
+	if err := checkBooleanExpressionSyntax(body); err != nil {
+		return nil, err
+	}
+
+	return infixToExpressionTree(body)
+}
+
+// expressionTokens consumes every token that can be part of an expression.
+// Constructs that can't be handled by the shunting-yard algorithm, like CASE
+// and function calls, are parsed on their own and collapsed into a single
+// subexpression token. An enclosed expression, such as a function argument,
+// ends at the first closing parenthesis that it didn't open.
+func (p *parser) expressionTokens(enclosed bool) ([]token, error) {
+	tokens := make([]token, 0)
+	depth := 0
+
 	for {
+		if p.lookahead._type == caseKeyword {
+			tk := p.lookahead
+
+			expr, err := p.caseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{
+				_type:    subexpression,
+				strValue: tk.strValue,
+				goValue:  expr,
+
+				line:   tk.line,
+				column: tk.column,
+			})
+			continue
+		}
+
+		if p.lookahead._type == leftBracket {
+			tk := p.lookahead
+
+			// a bracket right after an operand indexes it,
+			// anywhere else it starts an array literal
+			if n := len(tokens); n > 0 && (tokens[n-1].isOperand() || tokens[n-1].isRightParenthesis()) {
+				expr, err := p.arrayIndex()
+				if err != nil {
+					return nil, err
+				}
+
+				tokens = append(tokens, token{
+					_type:    index,
+					strValue: tk.strValue,
+
+					line:   tk.line,
+					column: tk.column,
+				}, token{
+					_type:    subexpression,
+					strValue: tk.strValue,
+					goValue:  expr,
+
+					line:   expr.Line,
+					column: expr.Column,
+				})
+				continue
+			}
+
+			expr, err := p.arrayLiteral()
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{
+				_type:    subexpression,
+				strValue: tk.strValue,
+				goValue:  expr,
+
+				line:   tk.line,
+				column: tk.column,
+			})
+			continue
+		}
+
 		if !p.lookahead.isPredicateToken() {
 			break
 		}
 
+		if p.lookahead.isLeftParenthesis() {
+			depth++
+		} else if p.lookahead.isRightParenthesis() {
+			if enclosed && depth == 0 {
+				break
+			}
+			depth--
+		}
+
 		tk, err := p.consume()
 		if err != nil {
 			return nil, err
 		}
 
-		body = append(body, tk)
+		// a minus with no operand before it to subtract from negates the
+		// operand after it, which is the same as subtracting it from zero
+		if n := len(tokens); tk._type == subtract && (n == 0 || !tokens[n-1].isOperand() && !tokens[n-1].isRightParenthesis()) {
+			tokens = append(tokens, token{
+				_type:    numberLiteral,
+				strValue: "0",
+				goValue:  int64(0),
+
+				line:   tk.line,
+				column: tk.column,
+			})
+			tk._type = negate
+		}
+
+		if tk._type == identifier && p.lookahead.isLeftParenthesis() {
+			expr, err := p.functionCall(tk)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{
+				_type:    subexpression,
+				strValue: tk.strValue,
+				goValue:  expr,
+
+				line:   tk.line,
+				column: tk.column,
+			})
+			continue
+		}
+
+		tokens = append(tokens, tk)
 	}
 
-	if len(body) == 0 {
-		return nil, errors.New("expected predicate after 'WHERE', but got nothing")
+	return tokens, nil
+}
+
+// expression parses a complete expression, keyword is used to give context
+// to the error when there's nothing to be parsed.
+func (p *parser) expression(keyword string, enclosed bool) (*eval.Expression, error) {
+	line, column := p.validLine(), p.validColumn()
+
+	tokens, err := p.expressionTokens(enclosed)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := checkParenthesesBalance(body); err != nil {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("expected expression after '%s', but got '%s' at %d:%d", keyword, p.lookahead.strValue, line, column)
+	}
+
+	if err := checkParenthesesBalance(tokens); err != nil {
 		return nil, err
 	}
 
-	if err := checkBooleanExpressionSyntax(body); err != nil {
+	if err := checkBooleanExpressionSyntax(tokens); err != nil {
 		return nil, err
 	}
 
-	return infixToExpressionTree(body)
+	return infixToExpressionTree(tokens)
+}
+
+// caseExpression parses both forms of CASE:
+//
+//	CASE WHEN cond THEN result [WHEN ...] [ELSE result] END
+//	CASE subject WHEN value THEN result [WHEN ...] [ELSE result] END
+func (p *parser) caseExpression() (*eval.Expression, error) {
+	tk, err := p.consume()
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &eval.Expression{
+		Type: eval.Case,
+
+		Line:   tk.line,
+		Column: tk.column,
+	}
+
+	if p.lookahead._type != whenKeyword {
+		subject, err := p.expression("CASE", false)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.Subject = subject
+	}
+
+	if p.lookahead._type != whenKeyword {
+		return nil, fmt.Errorf("expected 'WHEN', but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	for p.lookahead._type == whenKeyword {
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		when, err := p.expression("WHEN", false)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.lookahead._type != thenKeyword {
+			return nil, fmt.Errorf("expected 'THEN', but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		then, err := p.expression("THEN", false)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.Branches = append(expr.Branches, &eval.CaseBranch{When: when, Then: then})
+	}
+
+	if p.lookahead._type == elseKeyword {
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		els, err := p.expression("ELSE", false)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.Else = els
+	}
+
+	if p.lookahead._type != endKeyword {
+		return nil, fmt.Errorf("expected 'END', but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// functionCall parses the arguments of a call to the function named by the
+// given token, EXTRACT is written as EXTRACT(field FROM source) and
+// results in the same call as EXTRACT("field", source).
+func (p *parser) functionCall(name token) (*eval.Expression, error) {
+	tk, err := p.consume()
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &eval.Expression{
+		Type:     eval.Function,
+		Function: name.strValue,
+		Args:     []*eval.Expression{},
+
+		Line:   name.line,
+		Column: name.column,
+	}
+
+	if p.lookahead.isRightParenthesis() {
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		return expr, nil
+	}
+
+	if expr.Function == "extract" && p.lookahead._type == identifier {
+		field, err := p.consume()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.lookahead._type != clause || p.lookahead.strValue != string(From) {
+			return nil, fmt.Errorf("expected 'FROM', but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		source, err := p.expression("FROM", true)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.Args = append(expr.Args, &eval.Expression{
+			Type:    eval.Operand,
+			GoValue: field.strValue,
+
+			Line:   field.line,
+			Column: field.column,
+		}, source)
+	} else {
+		for {
+			arg, err := p.expression(name.strValue, true)
+			if err != nil {
+				return nil, err
+			}
+
+			expr.Args = append(expr.Args, arg)
+
+			if p.lookahead._type != comma {
+				break
+			}
+
+			if _, err := p.consume(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !p.lookahead.isRightParenthesis() {
+		return nil, fmt.Errorf("expected closing parenthesis for the one at %d:%d, but got '%s' at %d:%d", tk.line, tk.column, p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// arrayLiteral parses the elements of an array between brackets,
+// which is the same as calling the array function with them.
+func (p *parser) arrayLiteral() (*eval.Expression, error) {
+	tk, err := p.consume()
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &eval.Expression{
+		Type:     eval.Function,
+		Function: "array",
+		Args:     []*eval.Expression{},
+
+		Line:   tk.line,
+		Column: tk.column,
+	}
+
+	for p.lookahead._type != rightBracket {
+		e, err := p.expression("[", false)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.Args = append(expr.Args, e)
+
+		if p.lookahead._type != comma {
+			break
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.closingBracket(tk); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// arrayIndex parses the expression between the brackets of an index.
+func (p *parser) arrayIndex() (*eval.Expression, error) {
+	tk, err := p.consume()
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := p.expression("[", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.closingBracket(tk); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+func (p *parser) closingBracket(opening token) error {
+	if p.lookahead._type != rightBracket {
+		return fmt.Errorf("expected closing bracket for the one at %d:%d, but got '%s' at %d:%d", opening.line, opening.column, p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	_, err := p.consume()
+	return err
+}
+
+// withBody parses the options of CREATE TABLE, given as a list
+// of assignments of strings enclosed in parentheses.
+func (p *parser) withBody() (any, error) {
+	options := schema.TableOptions{}
+
+	if !p.lookahead.isLeftParenthesis() {
+		return nil, fmt.Errorf("expected opening parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.lookahead._type != identifier {
+			return nil, fmt.Errorf("expected table option, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		name, err := p.consume()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.lookahead._type != assign {
+			return nil, fmt.Errorf("expected '=', but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+
+		if p.lookahead._type != stringLiteral {
+			return nil, fmt.Errorf("expected string literal, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+		}
+
+		value, err := p.consume()
+		if err != nil {
+			return nil, err
+		}
+
+		switch name.strValue {
+		case "compression":
+			options.Compression = schema.Compression(value.strValue)
+			if err := schema.CheckCompression(options.Compression); err != nil {
+				return nil, fmt.Errorf("%w at %d:%d", err, value.line, value.column)
+			}
+		case "layout":
+			options.Layout = schema.Layout(value.strValue)
+			if err := schema.CheckLayout(options.Layout); err != nil {
+				return nil, fmt.Errorf("%w at %d:%d", err, value.line, value.column)
+			}
+		default:
+			return nil, fmt.Errorf("table option '%s' does not exist at %d:%d", name.strValue, name.line, name.column)
+		}
+
+		if p.lookahead._type != comma {
+			break
+		}
+
+		if _, err := p.consume(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !p.lookahead.isRightParenthesis() {
+		return nil, fmt.Errorf("expected closing parenthesis, but got '%s' at %d:%d", p.lookahead.strValue, p.validLine(), p.validColumn())
+	}
+
+	if _, err := p.consume(); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// vacuumBody parses the table of VACUUM, which is left out to vacuum every table.
+func (p *parser) vacuumBody() (any, error) {
+	if p.lookahead._type != identifier {
+		return "", nil
+	}
+
+	return p.identifier()
 }
 
 func (p *parser) identifier() (any, error) {
@@ -381,6 +1109,9 @@ func infixToPostfix(tokens []token) ([]token, error) {
 			}
 		} else if tk.isOperand() {
 			postfix = append(postfix, tk)
+		} else if tk._type == negate {
+			// its operand is still to come, so there's nothing to pop
+			s.push(tk)
 		} else if tk.isOperator() {
 			for tki := s.pop(); tki != tokenNoop; tki = s.pop() {
 				if tk.hasLowerOrSamePrecedenceThan(tki) && !tki.isLeftParenthesis() {
@@ -413,10 +1144,15 @@ func postfixToExpressionTree(tokens []token) (*eval.Expression, error) {
 	s := stack[*eval.Expression]{}
 
 	for _, tk := range tokens {
-		if tk.isOperand() {
+		if tk._type == subexpression {
+			s.push(tk.goValue.(*eval.Expression))
+		} else if tk.isOperand() {
 			expr := &eval.Expression{
 				Type:    eval.Operand,
 				GoValue: tk.goValue,
+
+				Line:   tk.line,
+				Column: tk.column,
 			}
 			if tk._type == identifier {
 				expr.Identifier = tk.strValue
@@ -426,15 +1162,23 @@ func postfixToExpressionTree(tokens []token) (*eval.Expression, error) {
 			right := s.pop()
 			left := s.pop()
 
-			if !eval.IsOperator(string(tk._type)) {
+			operator := eval.OperatorType(tk._type)
+			if tk._type == negate {
+				operator = eval.Subtract
+			}
+
+			if !eval.IsOperator(string(operator)) {
 				return nil, fmt.Errorf("token '%s' at %d:%d is not a valid operator", tk.strValue, tk.line, tk.column)
 			}
 
 			e := &eval.Expression{
 				Type:     eval.Operator,
-				Operator: eval.OperatorType(tk._type),
+				Operator: operator,
 				Left:     left,
 				Right:    right,
+
+				Line:   tk.line,
+				Column: tk.column,
 			}
 
 			s.push(e)
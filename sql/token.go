@@ -1,8 +1,14 @@
 package sql
 
 import (
+	"encoding/hex"
+	"errors"
 	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jvitoroc/gobase/eval"
 )
 
 type token struct {
@@ -31,18 +37,35 @@ func (tk *token) isRightParenthesis() bool {
 var (
 	logicalOperators    = []tokenType{and, or}
 	comparisonOperators = []tokenType{equal, notEqual, greaterEqual, greater, less, lessEqual}
-	operands            = []tokenType{identifier, numberLiteral, stringLiteral, booleanLiteral}
+	arithmeticOperators = []tokenType{add, subtract, negate, multiply, divide}
+	jsonOperators       = []tokenType{jsonExtract, jsonExtractText}
+	arrayOperators      = []tokenType{index, contains, containedBy, overlaps}
+	operands            = []tokenType{identifier, numberLiteral, stringLiteral, booleanLiteral, dateLiteral, timeLiteral, timestampLiteral, intervalLiteral, hexLiteral, uuidLiteral, subexpression}
 )
 
+// precedence of the operators, the lower the value the tighter it binds.
+// negate stands for a prefix minus, it binds tighter than any binary
+// operator so that it only applies to the operand right after it.
 var precedence = map[tokenType]int{
-	equal:        1,
-	notEqual:     1,
-	greaterEqual: 1,
-	greater:      1,
-	less:         1,
-	lessEqual:    1,
-	and:          2,
-	or:           3,
+	index:           0,
+	negate:          1,
+	jsonExtract:     2,
+	jsonExtractText: 2,
+	multiply:        3,
+	divide:          3,
+	add:             4,
+	subtract:        4,
+	equal:           5,
+	notEqual:        5,
+	greaterEqual:    5,
+	greater:         5,
+	less:            5,
+	lessEqual:       5,
+	contains:        5,
+	containedBy:     5,
+	overlaps:        5,
+	and:             6,
+	or:              7,
 }
 
 func (tk *token) hasLowerOrSamePrecedenceThan(tk1 token) bool {
@@ -72,24 +95,66 @@ func (tk *token) isOperand() bool {
 	return slices.Contains(operands, tk._type)
 }
 
+func (tk *token) isArithmeticOperator() bool {
+	return slices.Contains(arithmeticOperators, tk._type)
+}
+
+func (tk *token) isJSONOperator() bool {
+	return slices.Contains(jsonOperators, tk._type)
+}
+
+func (tk *token) isArrayOperator() bool {
+	return slices.Contains(arrayOperators, tk._type)
+}
+
 func (tk *token) isOperator() bool {
-	return tk.isComparisonOperator() || tk.isLogicalOperator()
+	return tk.isComparisonOperator() || tk.isLogicalOperator() || tk.isArithmeticOperator() || tk.isJSONOperator() || tk.isArrayOperator()
 }
 
-var literalTypes = []tokenType{numberLiteral, stringLiteral, booleanLiteral}
+var (
+	literalTypes      = []tokenType{numberLiteral, stringLiteral, booleanLiteral, dateLiteral, timeLiteral, timestampLiteral, intervalLiteral, hexLiteral, uuidLiteral}
+	typedLiteralTypes = []tokenType{dateLiteral, timeLiteral, timestampLiteral, intervalLiteral, hexLiteral, uuidLiteral}
+)
 
 func (tk *token) isLiteral() bool {
 	return slices.Contains(literalTypes, tk._type)
 }
 
+// isTypedLiteral tells if the token is a string literal
+// prefixed by its type, such as DATE "2024-01-31"
+func (tk *token) isTypedLiteral() bool {
+	return slices.Contains(typedLiteralTypes, tk._type)
+}
+
 func (tk *token) convertToGoType() (v any, err error) {
 	switch tk._type {
 	case numberLiteral:
+		// integers and decimals are kept exact, unless they have too many digits
+		if !strings.Contains(tk.strValue, ".") {
+			v, err = strconv.ParseInt(tk.strValue, 10, 64)
+			if err == nil || !errors.Is(err, strconv.ErrRange) {
+				return
+			}
+		} else if v, err = eval.ParseDecimal(tk.strValue); err == nil {
+			return
+		}
 		v, err = strconv.ParseFloat(tk.strValue, 64)
 	case booleanLiteral:
 		v, err = strconv.ParseBool(tk.strValue)
 	case stringLiteral:
 		v = tk.strValue
+	case dateLiteral:
+		v, err = eval.ParseDate(tk.strValue)
+	case timeLiteral:
+		v, err = eval.ParseTime(tk.strValue)
+	case timestampLiteral:
+		v, err = eval.ParseTimestamp(tk.strValue)
+	case intervalLiteral:
+		v, err = eval.ParseInterval(tk.strValue)
+	case hexLiteral:
+		v, err = hex.DecodeString(tk.strValue)
+	case uuidLiteral:
+		v, err = uuid.Parse(tk.strValue)
 	}
 
 	return
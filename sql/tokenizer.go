@@ -15,17 +15,43 @@ const (
 	booleanLiteral   tokenType = "boolean_literal"
 	stringLiteral    tokenType = "string_literal"
 	numberLiteral    tokenType = "number_literal"
+	dateLiteral      tokenType = "date_literal"
+	timeLiteral      tokenType = "time_literal"
+	timestampLiteral tokenType = "timestamp_literal"
+	intervalLiteral  tokenType = "interval_literal"
+	hexLiteral       tokenType = "hex_literal"
+	uuidLiteral      tokenType = "uuid_literal"
 	leftParenthesis  tokenType = "left_parenthesis"
 	rightParenthesis tokenType = "right_parenthesis"
 	and              tokenType = "and"
 	or               tokenType = "or"
+	caseKeyword      tokenType = "case"
+	whenKeyword      tokenType = "when"
+	thenKeyword      tokenType = "then"
+	elseKeyword      tokenType = "else"
+	endKeyword       tokenType = "end"
 	equal            tokenType = "equal"
+	assign           tokenType = "assign"
 	notEqual         tokenType = "not_equal"
 	greaterEqual     tokenType = "greater_equal"
 	greater          tokenType = "greater"
 	lessEqual        tokenType = "less_equal"
 	less             tokenType = "less"
+	add              tokenType = "add"
+	subtract         tokenType = "subtract"
+	negate           tokenType = "negate"
+	multiply         tokenType = "multiply"
+	divide           tokenType = "divide"
+	jsonExtract      tokenType = "json_extract"
+	jsonExtractText  tokenType = "json_extract_text"
+	leftBracket      tokenType = "left_bracket"
+	rightBracket     tokenType = "right_bracket"
+	index            tokenType = "index"
+	contains         tokenType = "contains"
+	containedBy      tokenType = "contained_by"
+	overlaps         tokenType = "overlaps"
 	identifier       tokenType = "identifier"
+	subexpression    tokenType = "subexpression"
 	whitespace       tokenType = "whitespace"
 	endOfStatement   tokenType = "end_of_statement"
 	invalid          tokenType = "invalid"
@@ -40,7 +66,31 @@ var (
 	regexps = []*tokenRegexps{
 		{
 			name:    clause,
-			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^(SELECT|FROM|(INSERT\s+INTO)|WHERE|(CREATE\s+TABLE)|DEFINITIONS|VALUES)\b`)},
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^(SELECT|FROM|(INSERT\s+INTO)|WHERE|(CREATE\s+TABLE)|DEFINITIONS|VALUES|(CREATE\s+TYPE)|(AS\s+ENUM)|(CREATE\s+(UNIQUE\s+)?INDEX)|(VERIFY\s+TABLE)|VACUUM|WITH)\b`)},
+		},
+		{
+			name:    dateLiteral,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^DATE\s+"[^"]*"`)},
+		},
+		{
+			name:    timeLiteral,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^TIME\s+"[^"]*"`)},
+		},
+		{
+			name:    timestampLiteral,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^TIMESTAMP\s+"[^"]*"`)},
+		},
+		{
+			name:    intervalLiteral,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^INTERVAL\s+"[^"]*"`)},
+		},
+		{
+			name:    uuidLiteral,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^UUID\s+"[^"]*"`)},
+		},
+		{
+			name:    hexLiteral,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^X"[^"]*"`)},
 		},
 		{
 			name:    dataType,
@@ -55,8 +105,13 @@ var (
 			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^(TRUE|FALSE)\b`)},
 		},
 		{
-			name:    stringLiteral,
-			regexps: []*regexp.Regexp{regexp.MustCompile(`^"([^"]*)"`)},
+			name: stringLiteral,
+			regexps: []*regexp.Regexp{
+				regexp.MustCompile(`^"([^"]*)"`),
+				// single quoted strings can hold double quotes,
+				// a single quote is escaped by doubling it
+				regexp.MustCompile(`^'([^']|'')*'`),
+			},
 		},
 		{
 			name:    numberLiteral,
@@ -70,6 +125,14 @@ var (
 			name:    rightParenthesis,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^\)`)},
 		},
+		{
+			name:    leftBracket,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^\[`)},
+		},
+		{
+			name:    rightBracket,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^\]`)},
+		},
 		{
 			name:    and,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^AND\b`)},
@@ -78,10 +141,34 @@ var (
 			name:    or,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^OR\b`)},
 		},
+		{
+			name:    caseKeyword,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^CASE\b`)},
+		},
+		{
+			name:    whenKeyword,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^WHEN\b`)},
+		},
+		{
+			name:    thenKeyword,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^THEN\b`)},
+		},
+		{
+			name:    elseKeyword,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^ELSE\b`)},
+		},
+		{
+			name:    endKeyword,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^END\b`)},
+		},
 		{
 			name:    equal,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^==`)},
 		},
+		{
+			name:    assign,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^=`)},
+		},
 		{
 			name:    notEqual,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^!=`)},
@@ -94,6 +181,18 @@ var (
 			name:    greater,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^>`)},
 		},
+		{
+			name:    containedBy,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^<@`)},
+		},
+		{
+			name:    contains,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^@>`)},
+		},
+		{
+			name:    overlaps,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^&&`)},
+		},
 		{
 			name:    lessEqual,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^<=`)},
@@ -102,6 +201,30 @@ var (
 			name:    less,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^<`)},
 		},
+		{
+			name:    jsonExtractText,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^->>`)},
+		},
+		{
+			name:    jsonExtract,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^->`)},
+		},
+		{
+			name:    add,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^\+`)},
+		},
+		{
+			name:    subtract,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^-`)},
+		},
+		{
+			name:    multiply,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^\*`)},
+		},
+		{
+			name:    divide,
+			regexps: []*regexp.Regexp{regexp.MustCompile(`^/`)},
+		},
 		{
 			name:    identifier,
 			regexps: []*regexp.Regexp{regexp.MustCompile(`^\w*`)},
@@ -174,7 +297,13 @@ func (t *tokenizer) getNextToken() (*token, error) {
 	}
 
 	if tk._type == "string_literal" {
-		tk.strValue = tk.strValue[1 : len(tk.strValue)-1]
+		if tk.strValue[0] == '\'' {
+			tk.strValue = strings.ReplaceAll(tk.strValue[1:len(tk.strValue)-1], "''", "'")
+		} else {
+			tk.strValue = tk.strValue[1 : len(tk.strValue)-1]
+		}
+	} else if tk.isTypedLiteral() {
+		tk.strValue = tk.strValue[strings.Index(tk.strValue, `"`)+1 : len(tk.strValue)-1]
 	} else {
 		tk.strValue = strings.ToLower(strings.Join(strings.Fields(tk.strValue), " "))
 	}